@@ -3,11 +3,55 @@
 
 package cmd
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
-	errCheckTerraformFiles       = errors.New("error checking Terraform files in directory")
-	errTerraformFilesDoNotExist  = errors.New("terraform files do not exist in directory")
 	errTerraformDirectoryMissing = errors.New("terraform directory does not exist")
 	errFetchVersionHTTPStatus    = errors.New("failed to fetch version")
+	errInvalidMode               = errors.New("invalid mode")
+	errRecursiveIncompatible     = errors.New("--recursive cannot be combined with --graph-file, --plan-json, --state, --tf-plan, --terragrunt, --workspace, --all-workspaces or --explain-classification")
+	errWorkspaceFlagsConflict    = errors.New("--workspace and --all-workspaces cannot be combined")
+	errInvalidParallelism        = errors.New("invalid parallelism")
+	errInvalidResourceTypeRegex  = errors.New("invalid resource type regex")
+	errRootModulesFailed         = errors.New("diagram generation failed for some root modules")
+)
+
+// optionFlags rewrites the Options fields named in pkg/terramaid errors as the run flags that set them. Longer
+// field names come first so that, e.g., Options.PlanJSON is not read as Options.Plan.
+var optionFlags = strings.NewReplacer(
+	"Options.ProviderSchemaFile", "--provider-schema-file",
+	"Options.ProviderSchema", "--provider-schema",
+	"Options.DrawCycles", "--draw-cycles",
+	"Options.GraphType", "--graph-type",
+	"Options.GraphFile", "--graph-file",
+	"Options.PlanJSON", "--plan-json",
+	"Options.ShowPlan", "--show-plan",
+	"Options.StatePull", "--state-pull",
+	"Options.TFPlan", "--tf-plan",
+	"Options.Plan", "--plan",
+	"Options.State", "--state",
+	"Options.VarFiles", "--var-file",
+	"Options.Vars", "--var",
+	"Options.Terragrunt", "--terragrunt",
+	"Options.ChartType", "--chart-type",
+	"Options.Format", "--format",
+	"Options.Engine", "--engine",
+	"Options.Mode", "--mode",
+	"Options.Workspace", "--workspace",
 )
+
+// flagError is an error from pkg/terramaid reworded in terms of run flags.
+type flagError struct {
+	err error
+}
+
+func (e flagError) Error() string {
+	return optionFlags.Replace(e.err.Error())
+}
+
+func (e flagError) Unwrap() error {
+	return e.err
+}
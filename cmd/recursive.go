@@ -0,0 +1,139 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/RoseSecurity/terramaid/internal"
+	"github.com/RoseSecurity/terramaid/pkg/terramaid"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	"github.com/fatih/color"
+)
+
+// rootResult is the outcome of generating the diagram for one root module.
+type rootResult struct {
+	root   string
+	output string
+	err    error
+}
+
+// generateRecursive discovers every root module under opts.WorkingDir and generates one diagram per root using
+// at most opts.Parallelism concurrent workers. It prints a per-root summary and returns an error if any root failed.
+func generateRecursive(ctx context.Context, opts *options) error {
+	switch {
+	case opts.GraphFile != "" || opts.PlanJSON != "" || opts.State != "" || opts.TFPlan != "" || opts.Terragrunt ||
+		opts.Workspace != "" || opts.AllWorkspaces || opts.ExplainClassification:
+		return errRecursiveIncompatible
+	case opts.Parallelism < 1:
+		return fmt.Errorf("%w %d: must be at least 1", errInvalidParallelism, opts.Parallelism)
+	case opts.Mode != terramaid.ModeTerraform && opts.Mode != terramaid.ModeStatic:
+		return fmt.Errorf("%w %q: valid options are %s, %s", errInvalidMode, opts.Mode, terramaid.ModeTerraform, terramaid.ModeStatic)
+	}
+
+	if !utils.DirExists(opts.WorkingDir) {
+		return fmt.Errorf("%w %q", errTerraformDirectoryMissing, opts.WorkingDir)
+	}
+
+	roots, err := internal.DiscoverRootModules(ctx, opts.WorkingDir, opts.Verbose)
+	if err != nil {
+		return fmt.Errorf("error discovering root modules: %w", err)
+	}
+
+	// Resolve the binary once so that workers do not each search PATH.
+	if opts.Mode == terramaid.ModeTerraform {
+		binary, err := terramaid.ResolveBinary(ctx, newGenerateOptions(opts))
+		if err != nil {
+			return err
+		}
+		opts.TFBinary = binary
+	}
+
+	results := make([]rootResult, len(roots))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(opts.Parallelism, len(roots)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = generateRoot(ctx, opts, roots[i])
+			}
+		}()
+	}
+	for i := range roots {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return summarizeRoots(results)
+}
+
+// generateRoot renders and writes the diagram for the root module at root, relative to opts.WorkingDir.
+func generateRoot(ctx context.Context, opts *options, root string) rootResult {
+	rootOpts := *opts
+	rootOpts.WorkingDir = filepath.Join(opts.WorkingDir, root)
+	rootOpts.Output = rootOutput(opts, root)
+	rootOpts.quiet = true
+
+	result := rootResult{root: root, output: rootOpts.Output}
+	if opts.Verbose {
+		utils.LogVerbose("Generating diagram for root module %s", rootOpts.WorkingDir)
+	}
+
+	diagram, err := renderDiagram(ctx, &rootOpts)
+	if err != nil {
+		result.err = flagError{err: err}
+		return result
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rootOpts.Output), 0o750); err != nil {
+		result.err = fmt.Errorf("error creating output directory: %w", err)
+		return result
+	}
+	if err := os.WriteFile(rootOpts.Output, []byte(diagram), 0o600); err != nil {
+		result.err = fmt.Errorf("error writing to file: %w", err)
+	}
+
+	return result
+}
+
+// rootOutput returns the diagram path for root: next to the root module, or mirrored under opts.OutputDir when set.
+func rootOutput(opts *options, root string) string {
+	name := filepath.Base(opts.Output)
+	if opts.OutputDir != "" {
+		return filepath.Join(opts.OutputDir, root, name)
+	}
+	return filepath.Join(opts.WorkingDir, root, name)
+}
+
+// summarizeRoots prints the outcome of every root module and returns an error when any of them failed.
+func summarizeRoots(results []rootResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+
+	fmt.Fprintf(color.Output, "\nGenerated %d of %d root module diagrams\n", len(results)-failed, len(results))
+	for _, result := range results {
+		if result.err != nil {
+			color.New(color.FgRed).Fprintf(color.Output, "  ✗ %s: %v\n", result.root, result.err)
+			continue
+		}
+		color.New(color.FgGreen).Fprintf(color.Output, "  ✓ %s -> %s\n", result.root, result.output)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", errRootModulesFailed, failed, len(results))
+	}
+
+	return nil
+}
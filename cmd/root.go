@@ -12,7 +12,7 @@ import (
 
 var rootCmd = &cobra.Command{
 	Use:           "terramaid",
-	Short:         "A utility for generating Mermaid diagrams from Terraform configurations",
+	Short:         "A utility for generating Mermaid and other diagrams from Terraform configurations",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Run: func(cmd *cobra.Command, args []string) {
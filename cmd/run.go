@@ -4,42 +4,86 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"regexp"
+	"strings"
+	"text/tabwriter"
 	"time"
 
-	"github.com/RoseSecurity/terramaid/internal"
+	"github.com/RoseSecurity/terramaid/pkg/terramaid"
 	"github.com/RoseSecurity/terramaid/pkg/utils"
-	"github.com/awalterschulze/gographviz"
 	"github.com/caarlos0/env/v11"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 type options struct {
-	WorkingDir       string        `env:"WORKING_DIR" envDefault:"."`
-	TFPlan           string        `env:"TF_PLAN"`
-	TFBinary         string        `env:"TF_BINARY"`
-	Output           string        `env:"OUTPUT" envDefault:"Terramaid.md"`
-	Direction        string        `env:"DIRECTION" envDefault:"TD"`
-	SubgraphName     string        `env:"SUBGRAPH_NAME" envDefault:"Terraform"`
-	ChartType        string        `env:"CHART_TYPE" envDefault:"flowchart"`
-	ResourcesOnly    bool          `env:"RESOURCES_ONLY" envDefault:"false"`
-	Verbose          bool          `env:"VERBOSE" envDefault:"false"`
-	Timeout          time.Duration `env:"TIMEOUT" envDefault:"0"`
-	IncludeTypes     []string      `env:"INCLUDE_TYPES" envSeparator:","`
-	ExcludeTypes     []string      `env:"EXCLUDE_TYPES" envSeparator:","`
-	IncludeProviders []string      `env:"INCLUDE_PROVIDERS" envSeparator:","`
-	ExcludeModules   []string      `env:"EXCLUDE_MODULES" envSeparator:","`
+	WorkingDir            string        `env:"WORKING_DIR" envDefault:"."`
+	Mode                  string        `env:"MODE" envDefault:"terraform"`
+	Recursive             bool          `env:"RECURSIVE" envDefault:"false"`
+	Parallelism           int           `env:"PARALLELISM" envDefault:"4"`
+	OutputDir             string        `env:"OUTPUT_DIR"`
+	Terragrunt            bool          `env:"TERRAGRUNT" envDefault:"false"`
+	TerragruntExpand      bool          `env:"TERRAGRUNT_EXPAND" envDefault:"false"`
+	TFPlan                string        `env:"TF_PLAN"`
+	Plan                  bool          `env:"PLAN" envDefault:"false"`
+	VarFiles              []string      `env:"VAR_FILE" envSeparator:","`
+	Vars                  []string      `env:"VAR" envSeparator:","`
+	Workspace             string        `env:"WORKSPACE"`
+	AllWorkspaces         bool          `env:"ALL_WORKSPACES" envDefault:"false"`
+	GraphType             string        `env:"GRAPH_TYPE"`
+	DrawCycles            bool          `env:"DRAW_CYCLES" envDefault:"false"`
+	GraphFile             string        `env:"GRAPH_FILE"`
+	PlanJSON              string        `env:"PLAN_JSON"`
+	ShowPlan              bool          `env:"SHOW_PLAN" envDefault:"false"`
+	State                 string        `env:"STATE"`
+	StatePull             bool          `env:"STATE_PULL" envDefault:"false"`
+	TFBinary              string        `env:"TF_BINARY"`
+	Engine                string        `env:"ENGINE" envDefault:"auto"`
+	Init                  string        `env:"INIT" envDefault:"upgrade"`
+	PluginDirs            []string      `env:"PLUGIN_DIR" envSeparator:","`
+	Lockfile              string        `env:"LOCKFILE"`
+	PluginCacheDir        string        `env:"PLUGIN_CACHE_DIR"`
+	Output                string        `env:"OUTPUT"`
+	Direction             string        `env:"DIRECTION" envDefault:"TD"`
+	SubgraphName          string        `env:"SUBGRAPH_NAME" envDefault:"Terraform"`
+	ChartType             string        `env:"CHART_TYPE" envDefault:"flowchart"`
+	Format                string        `env:"FORMAT" envDefault:"mermaid"`
+	GroupBy               string        `env:"GROUP_BY" envDefault:"module"`
+	IconMap               string        `env:"ICON_MAP"`
+	ResourcesOnly         bool          `env:"RESOURCES_ONLY" envDefault:"false"`
+	ProviderSchema        bool          `env:"PROVIDER_SCHEMA" envDefault:"false"`
+	ProviderSchemaFile    string        `env:"PROVIDER_SCHEMA_FILE"`
+	ExplainClassification bool          `env:"EXPLAIN_CLASSIFICATION" envDefault:"false"`
+	Verbose               bool          `env:"VERBOSE" envDefault:"false"`
+	Timeout               time.Duration `env:"TIMEOUT" envDefault:"0"`
+	IncludeTypes          []string      `env:"INCLUDE_TYPES" envSeparator:","`
+	ExcludeTypes          []string      `env:"EXCLUDE_TYPES" envSeparator:","`
+	IncludeProviders      []string      `env:"INCLUDE_PROVIDERS" envSeparator:","`
+	ExcludeModules        []string      `env:"EXCLUDE_MODULES" envSeparator:","`
+	IncludeAttributes     []string      `env:"INCLUDE_ATTRIBUTES" envSeparator:","`
+	ExcludeAttributes     []string      `env:"EXCLUDE_ATTRIBUTES" envSeparator:","`
+	ResourceTypeRegex     string        `env:"RESOURCE_TYPE_REGEX"`
+	ResourceTypePrefixes  []string      `env:"RESOURCE_TYPE_PREFIXES" envSeparator:","`
+
+	listChartTypes      bool           // Prints the chart types instead of generating a diagram
+	quiet               bool           // Suppresses the spinner when diagrams are generated concurrently
+	resourceTypeMatcher *regexp.Regexp // Compiled ResourceTypeRegex; nil when unset
 }
 
 var opts options // Global variable for flags and env variables
 
+// defaultOutputName is the name of the output file, without the extension of the output format, when --output is
+// not set.
+const defaultOutputName = "Terramaid"
+
 var runCmd = &cobra.Command{
 	Use:           "run",
-	Short:         "Generate Mermaid diagrams from Terraform configurations",
+	Short:         "Generate diagrams from Terraform configurations",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -51,49 +95,156 @@ var runCmd = &cobra.Command{
 			defer cancel()
 		}
 
-		return generateDiagrams(ctx, &opts)
+		if opts.listChartTypes {
+			return listChartTypes(color.Output)
+		}
+
+		if err := generateDiagrams(ctx, &opts); err != nil {
+			return flagError{err: err}
+		}
+		return nil
 	},
 }
 
-// generateDiagrams generates a Mermaid diagram from the Terraform configuration described by opts and writes it to opts.Output.
-// It validates the working directory and Terraform files, locates the Terraform binary if not provided, parses the Terraform graph,
-// applies filtering options from opts, generates the Mermaid flowchart, and writes the resulting diagram to the specified file.
+// generateDiagrams generates a diagram in opts.Format from the Terraform configuration described by opts and writes it to opts.Output.
+// It reads a pre-generated DOT graph when opts.GraphFile is set; otherwise it validates the working directory and Terraform files,
+// locates the Terraform binary if not provided, and parses the Terraform graph. It then applies filtering options from opts, renders the diagram, and writes the resulting diagram to the specified file.
 // It returns an error if the context is cancelled, validation fails, the Terraform binary cannot be found, parsing or diagram generation fails, or writing the output fails.
 func generateDiagrams(ctx context.Context, opts *options) error {
+	if opts.Output == "" {
+		opts.Output = defaultOutputName + terramaid.FormatExtension(opts.Format)
+	}
 	logRunOptions(opts)
-
-	if err := validateRun(ctx, opts); err != nil {
+	if err := newGenerateOptions(opts).Validate(); err != nil {
 		return err
 	}
+	if opts.ResourceTypeRegex != "" {
+		re, err := regexp.Compile(opts.ResourceTypeRegex)
+		if err != nil {
+			return fmt.Errorf("%w TERRAMAID_RESOURCE_TYPE_REGEX %q: %w", errInvalidResourceTypeRegex, opts.ResourceTypeRegex, err)
+		}
+		opts.resourceTypeMatcher = re
+	}
 
-	if err := configureTerraformBinary(opts); err != nil {
-		return err
+	if opts.Recursive {
+		return generateRecursive(ctx, opts)
+	}
+	if opts.Workspace != "" || opts.AllWorkspaces {
+		return generateWorkspaces(ctx, opts)
 	}
 
-	graph, err := parseTerraform(ctx, opts)
+	diagram, err := renderDiagram(ctx, opts)
 	if err != nil {
 		return err
 	}
 
-	mermaidDiagram, err := generateMermaid(ctx, graph, opts)
+	return writeDiagram(opts, diagram)
+}
+
+// renderDiagram builds the diagram for opts, either for a whole Terragrunt stack or for a single graph.
+func renderDiagram(ctx context.Context, opts *options) (string, error) {
+	graph, err := loadGraph(ctx, opts)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	return generateDiagram(ctx, graph, opts)
+}
+
+// loadGraph builds the graph described by opts, showing a spinner while the Terraform binary runs.
+func loadGraph(ctx context.Context, opts *options) (*terramaid.Graph, error) {
+	genOpts := newGenerateOptions(opts)
+	if genOpts.RunsTerraform() && !opts.quiet {
+		sp := utils.NewSpinner("Generating Terramaid Diagrams")
+		sp.Start()
+		defer sp.Stop()
 	}
 
-	return writeMermaid(opts, mermaidDiagram)
+	return terramaid.LoadGraph(ctx, genOpts)
 }
 
 func logRunOptions(opts *options) {
 	if opts.Verbose {
 		utils.LogVerbose("Starting Terramaid with the following options:")
 		utils.LogVerbose("- Working Directory: %s", opts.WorkingDir)
+		utils.LogVerbose("- Mode: %s", opts.Mode)
+		if opts.Recursive {
+			utils.LogVerbose("- Recursive: %t (parallelism: %d)", opts.Recursive, opts.Parallelism)
+		}
+		if opts.OutputDir != "" {
+			utils.LogVerbose("- Output Directory: %s", opts.OutputDir)
+		}
+		if opts.Terragrunt {
+			utils.LogVerbose("- Terragrunt: %t (expand units: %t)", opts.Terragrunt, opts.TerragruntExpand)
+		}
 		utils.LogVerbose("- Terraform Plan: %s", opts.TFPlan)
+		if opts.Plan {
+			utils.LogVerbose("- Speculative Plan: %t", opts.Plan)
+		}
+		if len(opts.VarFiles) > 0 {
+			utils.LogVerbose("- Var Files: %v", opts.VarFiles)
+		}
+		if len(opts.Vars) > 0 {
+			utils.LogVerbose("- Vars: %d assignments", len(opts.Vars))
+		}
+		if opts.GraphType != "" {
+			utils.LogVerbose("- Graph Type: %s", opts.GraphType)
+		}
+		if opts.Workspace != "" {
+			utils.LogVerbose("- Workspace: %s", opts.Workspace)
+		}
+		if opts.AllWorkspaces {
+			utils.LogVerbose("- All Workspaces: %t", opts.AllWorkspaces)
+		}
+		if opts.DrawCycles {
+			utils.LogVerbose("- Draw Cycles: %t", opts.DrawCycles)
+		}
+		if opts.GraphFile != "" {
+			utils.LogVerbose("- Graph File: %s", opts.GraphFile)
+		}
+		if opts.PlanJSON != "" {
+			utils.LogVerbose("- Plan JSON: %s", opts.PlanJSON)
+		}
+		if opts.ShowPlan {
+			utils.LogVerbose("- Show Plan: %t", opts.ShowPlan)
+		}
+		if opts.State != "" {
+			utils.LogVerbose("- State File: %s", opts.State)
+		}
+		if opts.StatePull {
+			utils.LogVerbose("- State Pull: %t", opts.StatePull)
+		}
 		utils.LogVerbose("- Terraform Binary: %s", opts.TFBinary)
+		utils.LogVerbose("- Engine: %s", opts.Engine)
+		utils.LogVerbose("- Init: %s", opts.Init)
+		if len(opts.PluginDirs) > 0 {
+			utils.LogVerbose("- Plugin Dirs: %v", opts.PluginDirs)
+		}
+		if opts.Lockfile != "" {
+			utils.LogVerbose("- Lockfile: %s", opts.Lockfile)
+		}
+		if opts.PluginCacheDir != "" {
+			utils.LogVerbose("- Plugin Cache Dir: %s", opts.PluginCacheDir)
+		}
 		utils.LogVerbose("- Output File: %s", opts.Output)
 		utils.LogVerbose("- Direction: %s", opts.Direction)
 		utils.LogVerbose("- Subgraph Name: %s", opts.SubgraphName)
 		utils.LogVerbose("- Chart Type: %s", opts.ChartType)
+		utils.LogVerbose("- Format: %s", opts.Format)
+		utils.LogVerbose("- Group By: %s", opts.GroupBy)
+		if opts.IconMap != "" {
+			utils.LogVerbose("- Icon Map: %s", opts.IconMap)
+		}
 		utils.LogVerbose("- Resources Only: %t", opts.ResourcesOnly)
+		if opts.ProviderSchema {
+			utils.LogVerbose("- Provider Schema: %t", opts.ProviderSchema)
+		}
+		if opts.ProviderSchemaFile != "" {
+			utils.LogVerbose("- Provider Schema File: %s", opts.ProviderSchemaFile)
+		}
+		if opts.ExplainClassification {
+			utils.LogVerbose("- Explain Classification: %t", opts.ExplainClassification)
+		}
 		if opts.Timeout > 0 {
 			utils.LogVerbose("- Timeout: %s", opts.Timeout)
 		}
@@ -109,115 +260,130 @@ func logRunOptions(opts *options) {
 		if len(opts.ExcludeModules) > 0 {
 			utils.LogVerbose("- Exclude Modules: %v", opts.ExcludeModules)
 		}
-	}
-}
-
-func validateRun(ctx context.Context, opts *options) error {
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-	}
-
-	if opts.WorkingDir != "" {
-		exists, err := utils.TerraformFilesExist(opts.WorkingDir)
-		if err != nil {
-			return fmt.Errorf("%w %q: %w", errCheckTerraformFiles, opts.WorkingDir, err)
-		}
-		if !exists {
-			return fmt.Errorf("%w %q", errTerraformFilesDoNotExist, opts.WorkingDir)
+		if len(opts.IncludeAttributes) > 0 {
+			utils.LogVerbose("- Include Attributes: %v", opts.IncludeAttributes)
 		}
-		if opts.Verbose {
-			utils.LogVerbose("Confirmed Terraform files exist in %s", opts.WorkingDir)
+		if len(opts.ExcludeAttributes) > 0 {
+			utils.LogVerbose("- Exclude Attributes: %v", opts.ExcludeAttributes)
 		}
-	}
-
-	// Validate directories and files
-	if opts.WorkingDir != "" && !utils.DirExists(opts.WorkingDir) {
-		return fmt.Errorf("%w %q", errTerraformDirectoryMissing, opts.WorkingDir)
-	}
-
-	return nil
-}
-
-func configureTerraformBinary(opts *options) error {
-	if opts.TFBinary == "" {
-		tfBinary, err := exec.LookPath("terraform")
-		if err != nil {
-			return fmt.Errorf("error finding Terraform binary: %w", err)
+		if opts.ResourceTypeRegex != "" {
+			utils.LogVerbose("- Resource Type Regex: %s", opts.ResourceTypeRegex)
 		}
-		opts.TFBinary = tfBinary
-		if opts.Verbose {
-			utils.LogVerbose("Terraform binary found at: %s", opts.TFBinary)
+		if len(opts.ResourceTypePrefixes) > 0 {
+			utils.LogVerbose("- Resource Type Prefixes: %v", opts.ResourceTypePrefixes)
 		}
 	}
-
-	return nil
 }
 
-func parseTerraform(ctx context.Context, opts *options) (*gographviz.Graph, error) {
-	// Spinner initialization and graph parsing
-	sp := utils.NewSpinner("Generating Terramaid Diagrams")
-	sp.Start()
-	defer sp.Stop()
-
-	if opts.Verbose {
-		utils.LogVerbose("Initializing Terraform and building graph...")
+// generateDiagram renders graph in opts.Format, printing the classification report when it is requested.
+func generateDiagram(ctx context.Context, graph *terramaid.Graph, opts *options) (string, error) {
+	genOpts := newGenerateOptions(opts)
+	var report bytes.Buffer
+	if opts.ExplainClassification {
+		genOpts.ClassificationReport = &report
 	}
 
-	graph, err := internal.ParseTerraform(ctx, opts.WorkingDir, opts.TFBinary, opts.TFPlan, opts.Verbose)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing Terraform: %w", err)
+	var sb strings.Builder
+	if err := terramaid.Render(ctx, graph, genOpts, &sb); err != nil {
+		return "", err
 	}
 
-	// Respect context cancellation after heavy parsing
-	if err := ctx.Err(); err != nil {
-		return nil, err
+	if opts.ExplainClassification {
+		fmt.Fprintf(color.Output, "\nNode classification for %s:\n", opts.Output)
+		if _, err := report.WriteTo(color.Output); err != nil {
+			return "", fmt.Errorf("error writing classification report: %w", err)
+		}
 	}
 
-	return graph, nil
+	return sb.String(), nil
 }
 
-func generateMermaid(ctx context.Context, graph *gographviz.Graph, opts *options) (string, error) {
-	if opts.Verbose {
-		utils.LogVerbose("Generating Mermaid flowchart...")
-	}
-
-	// Create filter configuration
-	filter := &internal.FilterConfig{
-		IncludeTypes:     opts.IncludeTypes,
-		ExcludeTypes:     opts.ExcludeTypes,
-		IncludeProviders: opts.IncludeProviders,
-		ExcludeModules:   opts.ExcludeModules,
+// newGenerateOptions creates the diagram generation options from opts.
+func newGenerateOptions(opts *options) terramaid.Options {
+	return terramaid.Options{
+		WorkingDir:           opts.WorkingDir,
+		Mode:                 opts.Mode,
+		Terragrunt:           opts.Terragrunt,
+		TerragruntExpand:     opts.TerragruntExpand,
+		GraphFile:            opts.GraphFile,
+		PlanJSON:             opts.PlanJSON,
+		State:                opts.State,
+		TFPlan:               opts.TFPlan,
+		Plan:                 opts.Plan,
+		VarFiles:             opts.VarFiles,
+		Vars:                 opts.Vars,
+		ShowPlan:             opts.ShowPlan,
+		StatePull:            opts.StatePull,
+		Workspace:            opts.Workspace,
+		GraphType:            opts.GraphType,
+		DrawCycles:           opts.DrawCycles,
+		TFBinary:             opts.TFBinary,
+		Engine:               opts.Engine,
+		Init:                 opts.Init,
+		PluginDirs:           opts.PluginDirs,
+		Lockfile:             opts.Lockfile,
+		PluginCacheDir:       opts.PluginCacheDir,
+		Direction:            opts.Direction,
+		SubgraphName:         opts.SubgraphName,
+		ChartType:            opts.ChartType,
+		Format:               opts.Format,
+		GroupBy:              opts.GroupBy,
+		IconMapFile:          opts.IconMap,
+		ResourcesOnly:        opts.ResourcesOnly,
+		ProviderSchema:       opts.ProviderSchema,
+		ProviderSchemaFile:   opts.ProviderSchemaFile,
+		ResourceTypeMatcher:  opts.resourceTypeMatcher,
+		ResourceTypePrefixes: resourceTypePrefixes(opts.ResourceTypePrefixes),
+		IncludeTypes:         opts.IncludeTypes,
+		ExcludeTypes:         opts.ExcludeTypes,
+		IncludeAttributes:    opts.IncludeAttributes,
+		ExcludeAttributes:    opts.ExcludeAttributes,
+		IncludeProviders:     opts.IncludeProviders,
+		ExcludeModules:       opts.ExcludeModules,
+		Verbose:              opts.Verbose,
 	}
+}
 
-	mermaidDiagram, err := internal.GenerateMermaidFlowchart(ctx, graph, opts.Direction, opts.SubgraphName, opts.ResourcesOnly, filter, opts.Verbose)
-	if err != nil {
-		return "", fmt.Errorf("error generating Mermaid diagram: %w", err)
+// resourceTypePrefixes trims the TERRAMAID_RESOURCE_TYPE_PREFIXES entries and drops empty ones.
+func resourceTypePrefixes(prefixes []string) []string {
+	var out []string
+	for _, p := range prefixes {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
 	}
+	return out
+}
 
-	if err := ctx.Err(); err != nil {
-		return "", err
+// listChartTypes writes the supported chart types and their descriptions to w.
+func listChartTypes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, chartType := range terramaid.ChartTypes() {
+		fmt.Fprintf(tw, "%s\t%s\n", chartType.Name, chartType.Description)
 	}
-
-	return mermaidDiagram, nil
+	return tw.Flush()
 }
 
-func writeMermaid(opts *options, mermaidDiagram string) error {
+// writeDiagram writes diagram to opts.Output and reports where it was written.
+func writeDiagram(opts *options, diagram string) error {
+	name := "Diagram"
+	if opts.Format == "mermaid" {
+		name = "Mermaid diagram"
+	}
 	if opts.Verbose {
-		utils.LogVerbose("Writing Mermaid diagram to %s", opts.Output)
+		utils.LogVerbose("Writing %s to %s", strings.ToLower(name), opts.Output)
 	}
-	if err := os.WriteFile(opts.Output, []byte(mermaidDiagram), 0o600); err != nil {
+	if err := os.WriteFile(opts.Output, []byte(diagram), 0o600); err != nil {
 		return fmt.Errorf("error writing to file: %w", err)
 	}
 
-	color.New(color.FgGreen).Fprintf(color.Output, "\nMermaid diagram successfully written to %s\n", opts.Output)
+	color.New(color.FgGreen).Fprintf(color.Output, "\n%s successfully written to %s\n", name, opts.Output)
 
 	return nil
 }
 
 // init parses environment variables prefixed with TERRAMAID_ and binds command-line flags to the package options.
-// It prints any environment parsing error to stdout, registers flags (output, direction, subgraph-name, chart-type, tf-plan, tf-binary, working-dir, verbose, resources-only, timeout, include-types, exclude-types, include-providers, exclude-modules) onto runCmd, and disables Cobra's auto-generated documentation tag.
+// It prints any environment parsing error to stdout, registers flags (output, direction, subgraph-name, chart-type, list-chart-types, format, group-by, icon-map, tf-plan, plan, var-file, var, workspace, all-workspaces, graph-type, draw-cycles, graph-file, plan-json, show-plan, state, state-pull, tf-binary, engine, init, plugin-dir, lockfile, plugin-cache-dir, working-dir, mode, recursive, parallelism, output-dir, terragrunt, terragrunt-expand, verbose, resources-only, provider-schema, provider-schema-file, explain-classification, timeout, include-types, exclude-types, include-providers, exclude-modules, include-attributes, exclude-attributes) onto runCmd, and disables Cobra's auto-generated documentation tag.
 func init() {
 	// Parse environment variables first, then bind flags to the opts struct
 	if err := env.ParseWithOptions(&opts, env.Options{Prefix: "TERRAMAID_"}); err != nil {
@@ -225,20 +391,52 @@ func init() {
 	}
 
 	// Bind flags to the opts struct
-	runCmd.Flags().StringVarP(&opts.Output, "output", "o", opts.Output, "Output file for Mermaid diagram (env: TERRAMAID_OUTPUT)")
+	runCmd.Flags().StringVarP(&opts.Output, "output", "o", opts.Output, "Output file for the diagram; defaults to Terramaid with the extension of --format, e.g. Terramaid.md (env: TERRAMAID_OUTPUT)")
 	runCmd.Flags().StringVarP(&opts.Direction, "direction", "r", opts.Direction, "Specify the direction of the diagram (env: TERRAMAID_DIRECTION)")
 	runCmd.Flags().StringVarP(&opts.SubgraphName, "subgraph-name", "s", opts.SubgraphName, "Specify the subgraph name of the diagram (env: TERRAMAID_SUBGRAPH_NAME)")
-	runCmd.Flags().StringVarP(&opts.ChartType, "chart-type", "c", opts.ChartType, "Specify the type of Mermaid chart to generate (env: TERRAMAID_CHART_TYPE)")
+	runCmd.Flags().StringVarP(&opts.ChartType, "chart-type", "c", opts.ChartType, "Specify the type of Mermaid chart to generate; see --list-chart-types (env: TERRAMAID_CHART_TYPE)")
+	runCmd.Flags().BoolVar(&opts.listChartTypes, "list-chart-types", false, "List the supported chart types and exit")
+	runCmd.Flags().StringVar(&opts.Format, "format", opts.Format, fmt.Sprintf("Output format: %s; formats other than mermaid draw flowcharts (env: TERRAMAID_FORMAT)", strings.Join(terramaid.Formats(), ", ")))
+	runCmd.Flags().StringVar(&opts.GroupBy, "group-by", opts.GroupBy, "Group architecture services and the nodes of PlantUML, D2 and DOT output by module or provider (env: TERRAMAID_GROUP_BY)")
+	runCmd.Flags().StringVar(&opts.IconMap, "icon-map", opts.IconMap, "JSON file mapping resource types or glob patterns to architecture icons, or - for stdin (env: TERRAMAID_ICON_MAP)")
 	runCmd.Flags().StringVarP(&opts.TFPlan, "tf-plan", "p", opts.TFPlan, "Path to Terraform plan file (env: TERRAMAID_TF_PLAN)")
+	runCmd.Flags().BoolVar(&opts.Plan, "plan", opts.Plan, "Graph a speculative terraform plan -refresh=false so count and for_each are expanded (env: TERRAMAID_PLAN)")
+	runCmd.Flags().StringSliceVar(&opts.VarFiles, "var-file", opts.VarFiles, "Variable file for --plan; may be repeated (env: TERRAMAID_VAR_FILE)")
+	runCmd.Flags().StringArrayVar(&opts.Vars, "var", opts.Vars, "Variable assignment name=value for --plan; may be repeated (env: TERRAMAID_VAR)")
+	runCmd.Flags().StringVar(&opts.Workspace, "workspace", opts.Workspace, "Terraform workspace to diagram; the output file is named after it (env: TERRAMAID_WORKSPACE)")
+	runCmd.Flags().BoolVar(&opts.AllWorkspaces, "all-workspaces", opts.AllWorkspaces, "Write one diagram per Terraform workspace, named after the workspace (env: TERRAMAID_ALL_WORKSPACES)")
+	runCmd.Flags().StringVar(&opts.GraphType, "graph-type", opts.GraphType, "Type of graph to build: plan, plan-destroy, plan-refresh-only, or apply (env: TERRAMAID_GRAPH_TYPE)")
+	runCmd.Flags().BoolVar(&opts.DrawCycles, "draw-cycles", opts.DrawCycles, "Highlight dependency cycles in the graph; requires --graph-type (env: TERRAMAID_DRAW_CYCLES)")
+	runCmd.Flags().StringVar(&opts.GraphFile, "graph-file", opts.GraphFile, "Path to a pre-generated terraform graph DOT file, or - for stdin; skips running Terraform (env: TERRAMAID_GRAPH_FILE)")
+	runCmd.Flags().StringVar(&opts.PlanJSON, "plan-json", opts.PlanJSON, "Path to terraform show -json plan output, or - for stdin; nodes show their planned action (env: TERRAMAID_PLAN_JSON)")
+	runCmd.Flags().BoolVar(&opts.ShowPlan, "show-plan", opts.ShowPlan, "Read --tf-plan or the --plan result with terraform show -json so nodes show their planned action (env: TERRAMAID_SHOW_PLAN)")
+	runCmd.Flags().StringVar(&opts.State, "state", opts.State, "Path to a Terraform state file, or - for stdin, to diagram deployed resources (env: TERRAMAID_STATE)")
+	runCmd.Flags().BoolVar(&opts.StatePull, "state-pull", opts.StatePull, "Diagram deployed resources from terraform state pull (env: TERRAMAID_STATE_PULL)")
 	runCmd.Flags().StringVarP(&opts.TFBinary, "tf-binary", "b", opts.TFBinary, "Path to Terraform binary (env: TERRAMAID_TF_BINARY)")
+	runCmd.Flags().StringVar(&opts.Engine, "engine", opts.Engine, "Engine to run: terraform, tofu, or auto to use tofu when terraform is not installed (env: TERRAMAID_ENGINE)")
+	runCmd.Flags().StringVar(&opts.Init, "init", opts.Init, "How to run terraform init: upgrade, standard, backend-false, or none to skip it (env: TERRAMAID_INIT)")
+	runCmd.Flags().StringSliceVar(&opts.PluginDirs, "plugin-dir", opts.PluginDirs, "Directory containing provider plugins, passed to terraform init -plugin-dir (env: TERRAMAID_PLUGIN_DIR)")
+	runCmd.Flags().StringVar(&opts.Lockfile, "lockfile", opts.Lockfile, "Dependency lock file mode passed to terraform init; only readonly is supported, and it skips -upgrade (env: TERRAMAID_LOCKFILE)")
+	runCmd.Flags().StringVar(&opts.PluginCacheDir, "plugin-cache-dir", opts.PluginCacheDir, "Provider plugin cache directory, exported to terraform init as TF_PLUGIN_CACHE_DIR (env: TERRAMAID_PLUGIN_CACHE_DIR)")
 	runCmd.Flags().StringVarP(&opts.WorkingDir, "working-dir", "w", opts.WorkingDir, "Working directory for Terraform (env: TERRAMAID_WORKING_DIR)")
+	runCmd.Flags().StringVar(&opts.Mode, "mode", opts.Mode, "How to build the graph: terraform runs terraform graph, static parses HCL without Terraform (env: TERRAMAID_MODE)")
+	runCmd.Flags().BoolVar(&opts.Recursive, "recursive", opts.Recursive, "Generate a diagram for every root module under the working directory (env: TERRAMAID_RECURSIVE)")
+	runCmd.Flags().IntVar(&opts.Parallelism, "parallelism", opts.Parallelism, "Number of root modules to process concurrently with --recursive (env: TERRAMAID_PARALLELISM)")
+	runCmd.Flags().StringVar(&opts.OutputDir, "output-dir", opts.OutputDir, "Directory for --recursive diagrams, mirroring the root module layout; defaults to next to each root (env: TERRAMAID_OUTPUT_DIR)")
+	runCmd.Flags().BoolVar(&opts.Terragrunt, "terragrunt", opts.Terragrunt, "Diagram every Terragrunt unit under the working directory and their dependencies (env: TERRAMAID_TERRAGRUNT)")
+	runCmd.Flags().BoolVar(&opts.TerragruntExpand, "terragrunt-expand", opts.TerragruntExpand, "Include each Terragrunt unit's internal resource graph (env: TERRAMAID_TERRAGRUNT_EXPAND)")
 	runCmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", opts.Verbose, "Enable verbose output (env: TERRAMAID_VERBOSE)")
 	runCmd.Flags().BoolVar(&opts.ResourcesOnly, "resources-only", opts.ResourcesOnly, "Only include resource-to-resource nodes and edges (env: TERRAMAID_RESOURCES_ONLY)")
+	runCmd.Flags().BoolVar(&opts.ProviderSchema, "provider-schema", opts.ProviderSchema, "Classify nodes as resources only if terraform providers schema -json defines their type (env: TERRAMAID_PROVIDER_SCHEMA)")
+	runCmd.Flags().StringVar(&opts.ProviderSchemaFile, "provider-schema-file", opts.ProviderSchemaFile, "Path to cached terraform providers schema -json output, or - for stdin, used like --provider-schema (env: TERRAMAID_PROVIDER_SCHEMA_FILE)")
+	runCmd.Flags().BoolVar(&opts.ExplainClassification, "explain-classification", opts.ExplainClassification, "Print each node and why it was kept in or dropped from the diagram (env: TERRAMAID_EXPLAIN_CLASSIFICATION)")
 	runCmd.Flags().DurationVarP(&opts.Timeout, "timeout", "t", opts.Timeout, "Timeout for the entire run (e.g. 5m) (env: TERRAMAID_TIMEOUT)")
 	runCmd.Flags().StringSliceVar(&opts.IncludeTypes, "include-types", opts.IncludeTypes, "Include only these resource types, supports glob patterns (env: TERRAMAID_INCLUDE_TYPES)")
 	runCmd.Flags().StringSliceVar(&opts.ExcludeTypes, "exclude-types", opts.ExcludeTypes, "Exclude these resource types, supports glob patterns (env: TERRAMAID_EXCLUDE_TYPES)")
-	runCmd.Flags().StringSliceVar(&opts.IncludeProviders, "include-providers", opts.IncludeProviders, "Include only resources from these providers (env: TERRAMAID_INCLUDE_PROVIDERS)")
+	runCmd.Flags().StringSliceVar(&opts.IncludeProviders, "include-providers", opts.IncludeProviders, "Include only resources from these providers, by name (aws), alias (aws.west) or source with an optional alias (hashicorp/aws, hashicorp/aws.west) (env: TERRAMAID_INCLUDE_PROVIDERS)")
 	runCmd.Flags().StringSliceVar(&opts.ExcludeModules, "exclude-modules", opts.ExcludeModules, "Exclude resources from these modules, supports glob patterns (env: TERRAMAID_EXCLUDE_MODULES)")
+	runCmd.Flags().StringSliceVar(&opts.IncludeAttributes, "include-attributes", opts.IncludeAttributes, "Show only these attributes in class diagrams, supports glob patterns; * shows all (env: TERRAMAID_INCLUDE_ATTRIBUTES)")
+	runCmd.Flags().StringSliceVar(&opts.ExcludeAttributes, "exclude-attributes", opts.ExcludeAttributes, "Hide these attributes in class diagrams, supports glob patterns (env: TERRAMAID_EXCLUDE_ATTRIBUTES)")
 
 	// Disable auto-generated string from documentation so that documentation is cleanly built and updated
 	runCmd.DisableAutoGenTag = true
@@ -0,0 +1,71 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateDiagrams_ResourceTypeRegex(t *testing.T) {
+	tests := []struct {
+		name    string
+		regex   string
+		wantErr error
+	}{
+		{name: "valid", regex: `^widget_`},
+		{name: "invalid", regex: `widget_(`, wantErr: errInvalidResourceTypeRegex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "widget_thing" "x" {}`), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			opts := &options{
+				WorkingDir:        dir,
+				Mode:              "static",
+				Direction:         "TD",
+				ChartType:         "flowchart",
+				Format:            "mermaid",
+				Output:            filepath.Join(dir, "Terramaid.md"),
+				ResourceTypeRegex: tt.regex,
+			}
+
+			err := generateDiagrams(context.Background(), opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("generateDiagrams() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, statErr := os.Stat(opts.Output); (statErr == nil) != (tt.wantErr == nil) {
+				t.Errorf("output written = %v, want %v", statErr == nil, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestFlagError(t *testing.T) {
+	err := generateDiagrams(context.Background(), &options{
+		WorkingDir: t.TempDir(),
+		Direction:  "TD",
+		ChartType:  "flowchart",
+		Format:     "mermaid",
+		Output:     filepath.Join(t.TempDir(), "Terramaid.md"),
+		DrawCycles: true,
+	})
+	if err == nil {
+		t.Fatal("generateDiagrams() error = nil, want an error")
+	}
+
+	want := "--draw-cycles requires --graph-type"
+	if got := (flagError{err: err}).Error(); got != want {
+		t.Errorf("flagError.Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(flagError{err: err}, err) {
+		t.Errorf("flagError does not wrap %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/pkg/terramaid"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// generateWorkspaces writes one diagram per Terraform workspace: the workspace named by opts.Workspace, or every
+// workspace when opts.AllWorkspaces is set. Each output file is named after its workspace.
+func generateWorkspaces(ctx context.Context, opts *options) error {
+	// The graph sources a workspace applies to are checked by terramaid.LoadWorkspaceGraphs, and --recursive by
+	// generateDiagrams before it gets here.
+	if opts.Workspace != "" && opts.AllWorkspaces {
+		return errWorkspaceFlagsConflict
+	}
+
+	var workspaces []string
+	if opts.Workspace != "" {
+		workspaces = []string{opts.Workspace}
+	}
+
+	graphs, err := loadWorkspaceGraphs(ctx, opts, workspaces)
+	if err != nil {
+		return err
+	}
+
+	for _, wg := range graphs {
+		wsOpts := *opts
+		wsOpts.Workspace = wg.Workspace
+		wsOpts.Output = workspaceOutput(opts.Output, wg.Workspace)
+
+		diagram, err := generateDiagram(ctx, wg.Graph, &wsOpts)
+		if err != nil {
+			return fmt.Errorf("workspace %q: %w", wg.Workspace, err)
+		}
+		if err := writeDiagram(&wsOpts, diagram); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadWorkspaceGraphs(ctx context.Context, opts *options, workspaces []string) ([]terramaid.WorkspaceGraph, error) {
+	if !opts.quiet {
+		sp := utils.NewSpinner("Generating Terramaid Diagrams")
+		sp.Start()
+		defer sp.Stop()
+	}
+
+	return terramaid.LoadWorkspaceGraphs(ctx, newGenerateOptions(opts), workspaces)
+}
+
+// workspaceOutput inserts the workspace name before the extension of output, e.g. Terramaid-dev.md.
+func workspaceOutput(output, workspace string) string {
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + "-" + workspace + ext
+}
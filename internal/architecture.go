@@ -0,0 +1,352 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// Chart types and groupings for architecture diagrams.
+const (
+	ChartTypeArchitecture = "architecture"
+	GroupByModule         = "module"
+	GroupByProvider       = "provider"
+)
+
+// defaultIcon is the built-in Mermaid icon used for resource types without a mapping and for groups.
+const defaultIcon = "cloud"
+
+var (
+	// archTitleUnsafe matches the characters Mermaid does not accept in architecture titles.
+	archTitleUnsafe = regexp.MustCompile(`[^\w ]+`)
+	// archIconName matches the icon names Mermaid accepts, e.g. server or logos:aws-lambda.
+	archIconName = regexp.MustCompile(`^[\w:-]+$`)
+	// defaultIcons maps resource types, or glob patterns of resource types, to icons from the Iconify logos pack.
+	defaultIcons = map[string]string{
+		"aws_api_gateway_rest_api":       "logos:aws-api-gateway",
+		"aws_apigatewayv2_api":           "logos:aws-api-gateway",
+		"aws_cloudfront_distribution":    "logos:aws-cloudfront",
+		"aws_cloudwatch_log_group":       "logos:aws-cloudwatch",
+		"aws_cloudwatch_metric_alarm":    "logos:aws-cloudwatch",
+		"aws_cognito_user_pool":          "logos:aws-cognito",
+		"aws_db_instance":                "logos:aws-rds",
+		"aws_dynamodb_table":             "logos:aws-dynamodb",
+		"aws_ecs_cluster":                "logos:aws-ecs",
+		"aws_ecs_service":                "logos:aws-ecs",
+		"aws_eks_cluster":                "logos:aws-eks",
+		"aws_elasticache_cluster":        "logos:aws-elasticache",
+		"aws_iam_policy":                 "logos:aws-iam",
+		"aws_iam_role":                   "logos:aws-iam",
+		"aws_instance":                   "logos:aws-ec2",
+		"aws_kinesis_stream":             "logos:aws-kinesis",
+		"aws_lambda_function":            "logos:aws-lambda",
+		"aws_lb":                         "logos:aws-elb",
+		"aws_rds_cluster":                "logos:aws-rds",
+		"aws_route53_record":             "logos:aws-route53",
+		"aws_route53_zone":               "logos:aws-route53",
+		"aws_s3_bucket":                  "logos:aws-s3",
+		"aws_secretsmanager_secret":      "logos:aws-secrets-manager",
+		"aws_sfn_state_machine":          "logos:aws-step-functions",
+		"aws_sns_topic":                  "logos:aws-sns",
+		"aws_sqs_queue":                  "logos:aws-sqs",
+		"aws_vpc":                        "logos:aws-vpc",
+		"google_cloud_run_service":       "logos:google-cloud-run",
+		"google_cloudfunctions_function": "logos:google-cloud-functions",
+		"aws_*":                          "logos:aws",
+		"azurerm_*":                      "logos:microsoft-azure",
+		"cloudflare_*":                   "logos:cloudflare-icon",
+		"github_*":                       "logos:github-icon",
+		"google_*":                       "logos:google-cloud",
+		"helm_*":                         "logos:helm",
+		"kubernetes_*":                   "logos:kubernetes",
+	}
+)
+
+func init() {
+	registerRenderer(ChartTypeArchitecture, architectureRenderer{})
+}
+
+// architectureRenderer renders the resources of a graph as a Mermaid architecture-beta diagram.
+type architectureRenderer struct{}
+
+func (architectureRenderer) Description() string {
+	return "Mermaid architecture-beta diagram of resources with service icons, grouped by module or provider"
+}
+
+// Render emits one service per resource, inside a group for its module or provider, and an edge for each
+// dependency between resources. Dependencies through variables, locals, outputs and modules are followed, so
+// resources that are only connected through them are still linked.
+func (architectureRenderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	sides, ok := architectureSides[opts.Direction]
+	if !ok {
+		return "", fmt.Errorf("%w %s: valid options are TB, TD, BT, RL, LR", errInvalidDirection, opts.Direction)
+	}
+	groupBy, err := validateGroupBy(opts.GroupBy)
+	if err != nil {
+		return "", err
+	}
+	for pattern, icon := range opts.IconMap {
+		if !archIconName.MatchString(icon) {
+			return "", fmt.Errorf("%w %q for %s", errInvalidIcon, icon, pattern)
+		}
+	}
+
+	filter := normalizeFilter(opts.Filter)
+	resources := collectResources(graph, filter, opts.Verbose)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	a := architecture{groupBy: groupBy, filter: filter, icons: opts.IconMap}
+	if opts.SubgraphName != "" {
+		a.root = "group_" + CleanID(opts.SubgraphName)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("```mermaid\narchitecture-beta\n")
+	if a.root != "" {
+		fmt.Fprintf(&sb, "    group %s(%s)[%s]\n", a.root, defaultIcon, archTitle(opts.SubgraphName))
+	}
+	a.appendGroups(&sb, resources)
+	for _, n := range resources.nodes {
+		fmt.Fprintf(&sb, "    service %s(%s)[%s]%s\n", resources.ids[n.ID], a.icon(n.Address.Type), archTitle(a.serviceLabel(n)), a.in(a.groupID(n)))
+	}
+	deps := resources.dependencies(graph)
+	for _, dep := range deps {
+		fmt.Fprintf(&sb, "    %s:%s --> %s:%s\n", dep[0], sides[0], sides[1], dep[1])
+	}
+	sb.WriteString("```\n")
+
+	if opts.Verbose {
+		utils.LogVerbose("Architecture diagram generation complete with %d services and %d edges", len(resources.nodes), len(deps))
+	}
+
+	return sb.String(), nil
+}
+
+// architectureSides maps a layout direction to the sides of the dependent and the dependency that edges connect.
+var architectureSides = map[string][2]string{
+	"TB": {"B", "T"},
+	"TD": {"B", "T"},
+	"BT": {"T", "B"},
+	"LR": {"R", "L"},
+	"RL": {"L", "R"},
+}
+
+// LoadIconMap reads a JSON object mapping resource types, or glob patterns such as aws_*, to Mermaid icon names
+// from path, or from stdin if path is "-".
+func LoadIconMap(ctx context.Context, path string, verbose bool) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if verbose {
+		utils.LogVerbose("Reading icon map from %s", describeInput(path))
+	}
+
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errReadIconMap, err)
+	}
+
+	var icons map[string]string
+	if err := json.Unmarshal(data, &icons); err != nil {
+		return nil, fmt.Errorf("%w: %w", errParseIconMap, err)
+	}
+	return icons, nil
+}
+
+// architecture holds the settings shared while writing one architecture diagram.
+type architecture struct {
+	groupBy string
+	filter  *FilterConfig
+	icons   map[string]string // User-supplied icons, consulted before defaultIcons
+	root    string            // ID of the group named after the subgraph; empty for none
+}
+
+// icon returns the icon for resourceType: an exact match in the user icon map, then the longest matching glob
+// pattern, then the same lookups in defaultIcons, and finally defaultIcon.
+func (a *architecture) icon(resourceType string) string {
+	for _, icons := range []map[string]string{a.icons, defaultIcons} {
+		if icon, ok := icons[resourceType]; ok {
+			return icon
+		}
+		best := ""
+		for pattern := range icons {
+			if len(pattern) > len(best) && strings.ContainsAny(pattern, "*?[") && matchesGlobPattern(resourceType, pattern) {
+				best = pattern
+			}
+		}
+		if best != "" {
+			return icons[best]
+		}
+	}
+	return defaultIcon
+}
+
+// serviceLabel returns the label of a resource; the module path is omitted when the resource is drawn in its
+// module's group.
+func (a *architecture) serviceLabel(n *model.Node) string {
+	if a.groupBy != GroupByModule {
+		return n.Label
+	}
+	addr := n.Address
+	addr.Module = nil
+	return addr.Label()
+}
+
+// groupPath returns the dot-joined names of the groups n is drawn in, or "" when it is not grouped.
+func (a *architecture) groupPath(n *model.Node) string {
+	if a.groupBy == GroupByModule {
+		return n.ModulePath()
+	}
+	return nodeProvider(n, a.filter)
+}
+
+// groupID returns the ID of the innermost group n is drawn in, or the root group when it is not grouped.
+func (a *architecture) groupID(n *model.Node) string {
+	path := a.groupPath(n)
+	if path == "" {
+		return a.root
+	}
+	return a.groupPrefix() + CleanID(path)
+}
+
+func (a *architecture) groupPrefix() string {
+	if a.groupBy == GroupByModule {
+		return "module_"
+	}
+	return "provider_"
+}
+
+// in returns the " in group" suffix that places a group or service in group, or "" for the top level.
+func (a *architecture) in(group string) string {
+	if group == "" {
+		return ""
+	}
+	return " in " + group
+}
+
+// appendGroups writes a group for every module or provider containing a resource. Nested modules are nested
+// groups; parents are written before their children.
+func (a *architecture) appendGroups(sb *strings.Builder, resources *resourceSet) {
+	paths := make(map[string]bool)
+	for _, n := range resources.nodes {
+		path := a.groupPath(n)
+		for path != "" {
+			paths[path] = true
+			if a.groupBy != GroupByModule {
+				break
+			}
+			path = path[:max(strings.LastIndex(path, "."), 0)]
+		}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	slices.Sort(sorted)
+
+	for _, path := range sorted {
+		parent, name := a.root, path
+		if i := strings.LastIndex(path, "."); i >= 0 && a.groupBy == GroupByModule {
+			parent, name = a.groupPrefix()+CleanID(path[:i]), path[i+1:]
+		}
+		fmt.Fprintf(sb, "    group %s(%s)[%s]%s\n", a.groupPrefix()+CleanID(path), defaultIcon, archTitle(name), a.in(parent))
+	}
+}
+
+// archTitle returns s with the characters Mermaid does not accept in architecture titles replaced by spaces.
+func archTitle(s string) string {
+	title := strings.TrimSpace(archTitleUnsafe.ReplaceAllString(s, " "))
+	if title == "" {
+		return "resource"
+	}
+	return title
+}
+
+// nodeProvider returns the provider type of n: the provider resolved by the filter, the provider recorded on the
+// node, or the prefix of its resource type.
+func nodeProvider(n *model.Node, filter *FilterConfig) string {
+	if ref, ok := filter.Providers[n.Label]; ok {
+		return ref.Type()
+	}
+	if n.Provider != "" {
+		return n.ProviderType()
+	}
+	return n.Address.Provider()
+}
+
+// resourceSet is the resources of a graph that a diagram draws, identified by their cleaned IDs.
+type resourceSet struct {
+	nodes []*model.Node     // One node per cleaned ID, in graph order
+	ids   map[string]string // Cleaned ID of every drawn node, keyed by node ID
+}
+
+// collectResources returns the resource nodes of graph that filter includes. terraform graph emits some
+// resources more than once, e.g. with (expand) and (close) suffixes; each is drawn once.
+func collectResources(graph *model.Graph, filter *FilterConfig, verbose bool) *resourceSet {
+	set := &resourceSet{ids: make(map[string]string)}
+	seen := make(map[string]bool)
+	for _, n := range graph.Nodes {
+		if resource, _ := filter.classifyNode(n); !resource || !filter.Includes(n, verbose) {
+			continue
+		}
+		id := CleanID(n.ID)
+		set.ids[n.ID] = id
+		if !seen[id] {
+			seen[id] = true
+			set.nodes = append(set.nodes, n)
+		}
+	}
+	return set
+}
+
+// dependencies returns the cleaned ID pairs of drawn resources where the first depends on the second, either
+// directly or through nodes that are not drawn. Pairs are in graph order without duplicates.
+func (s *resourceSet) dependencies(graph *model.Graph) [][2]string {
+	adjacent := make(map[string][]string)
+	for _, e := range graph.Edges {
+		adjacent[e.From] = append(adjacent[e.From], e.To)
+	}
+
+	var deps [][2]string
+	added := make(map[[2]string]bool)
+	for _, n := range graph.Nodes {
+		from, ok := s.ids[n.ID]
+		if !ok {
+			continue
+		}
+		visited := map[string]bool{n.ID: true}
+		queue := slices.Clone(adjacent[n.ID])
+		for len(queue) > 0 {
+			next := queue[0]
+			queue = queue[1:]
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			to, drawn := s.ids[next]
+			if !drawn {
+				queue = append(queue, adjacent[next]...)
+				continue
+			}
+			dep := [2]string{from, to}
+			if from != to && !added[dep] {
+				added[dep] = true
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps
+}
@@ -0,0 +1,159 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const architectureGraph = `digraph {
+	"[root] aws_lambda_function.api (expand)" [label = "aws_lambda_function.api"];
+	"[root] module.data.aws_s3_bucket.store (expand)" [label = "module.data.aws_s3_bucket.store"];
+	"[root] module.data.module.queue.aws_sqs_queue.jobs (expand)" [label = "module.data.module.queue.aws_sqs_queue.jobs"];
+	"[root] module.data.var.name (expand)" [label = "module.data.var.name"];
+	"[root] google_storage_bucket.backup (expand)" [label = "google_storage_bucket.backup"];
+	"[root] widget_thing.x (expand)" [label = "widget_thing.x"];
+	"[root] aws_lambda_function.api (expand)" -> "[root] module.data.var.name (expand)";
+	"[root] module.data.var.name (expand)" -> "[root] module.data.aws_s3_bucket.store (expand)";
+	"[root] aws_lambda_function.api (expand)" -> "[root] module.data.module.queue.aws_sqs_queue.jobs (expand)";
+	"[root] module.data.module.queue.aws_sqs_queue.jobs (expand)" -> "[root] module.data.module.queue.aws_sqs_queue.jobs (expand)";
+}
+`
+
+func TestArchitectureRenderer(t *testing.T) {
+	graph, err := parseGraph(architectureGraph, false)
+	if err != nil {
+		t.Fatalf("parseGraph() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		opts  RenderOptions
+		wants []string
+		avoid []string
+	}{
+		{
+			name: "module groups",
+			opts: RenderOptions{Direction: "TD", SubgraphName: "Terraform"},
+			wants: []string{
+				"```mermaid\narchitecture-beta\n",
+				"group group_Terraform(cloud)[Terraform]\n",
+				"group module_data(cloud)[data] in group_Terraform\n",
+				"group module_data_queue(cloud)[queue] in module_data\n",
+				"service aws_lambda_function_api(logos:aws-lambda)[aws_lambda_function api] in group_Terraform\n",
+				"service module_data_aws_s3_bucket_store(logos:aws-s3)[aws_s3_bucket store] in module_data\n",
+				"service module_data_module_queue_aws_sqs_queue_jobs(logos:aws-sqs)[aws_sqs_queue jobs] in module_data_queue\n",
+				"service google_storage_bucket_backup(logos:google-cloud)[google_storage_bucket backup] in group_Terraform\n",
+				"aws_lambda_function_api:B --> T:module_data_aws_s3_bucket_store\n",
+				"aws_lambda_function_api:B --> T:module_data_module_queue_aws_sqs_queue_jobs\n",
+				"service widget_thing_x(cloud)[widget_thing x] in group_Terraform\n",
+			},
+			avoid: []string{"var_name", "jobs:B --> T:module_data_module_queue_aws_sqs_queue_jobs"},
+		},
+		{
+			name: "provider groups",
+			opts: RenderOptions{Direction: "LR", GroupBy: GroupByProvider},
+			wants: []string{
+				"group provider_aws(cloud)[aws]\n",
+				"group provider_google(cloud)[google]\n",
+				"service module_data_aws_s3_bucket_store(logos:aws-s3)[module data aws_s3_bucket store] in provider_aws\n",
+				"aws_lambda_function_api:R --> L:module_data_aws_s3_bucket_store\n",
+			},
+			avoid: []string{"module_data(cloud)"},
+		},
+		{
+			name: "icon map",
+			opts: RenderOptions{Direction: "BT", IconMap: map[string]string{"aws_*": "server", "aws_s3_*": "disk", "google_storage_bucket": "database"}},
+			wants: []string{
+				"service aws_lambda_function_api(server)",
+				"service module_data_aws_s3_bucket_store(disk)",
+				"service google_storage_bucket_backup(database)",
+				"aws_lambda_function_api:T --> B:module_data_aws_s3_bucket_store\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagram, err := architectureRenderer{}.Render(context.Background(), graph, tt.opts)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(diagram, want) {
+					t.Errorf("Render() missing %q:\n%s", want, diagram)
+				}
+			}
+			for _, avoid := range tt.avoid {
+				if strings.Contains(diagram, avoid) {
+					t.Errorf("Render() contains %q:\n%s", avoid, diagram)
+				}
+			}
+		})
+	}
+}
+
+func TestArchitectureRenderer_Errors(t *testing.T) {
+	graph, err := parseGraph(architectureGraph, false)
+	if err != nil {
+		t.Fatalf("parseGraph() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    RenderOptions
+		wantErr error
+	}{
+		{name: "invalid direction", opts: RenderOptions{Direction: "XY"}, wantErr: errInvalidDirection},
+		{name: "invalid group by", opts: RenderOptions{Direction: "TD", GroupBy: "region"}, wantErr: errInvalidGroupBy},
+		{name: "invalid icon", opts: RenderOptions{Direction: "TD", IconMap: map[string]string{"aws_*": "aws icon)"}}, wantErr: errInvalidIcon},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (architectureRenderer{}).Render(context.Background(), graph, tt.opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Render() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadIconMap(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "icons.json")
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(valid, []byte(`{"aws_lambda_function": "server"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte(`["server"]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	icons, err := LoadIconMap(context.Background(), valid, false)
+	if err != nil || icons["aws_lambda_function"] != "server" {
+		t.Errorf("LoadIconMap() = %v, %v", icons, err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "missing", path: filepath.Join(dir, "missing.json"), wantErr: errReadIconMap},
+		{name: "not an object", path: invalid, wantErr: errParseIconMap},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadIconMap(context.Background(), tt.path, false); !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadIconMap() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
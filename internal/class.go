@@ -0,0 +1,147 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// ChartTypeClass is the chart type of class diagrams.
+const ChartTypeClass = "class"
+
+// maxMemberValue is the number of characters after which attribute values are truncated in class diagrams.
+const maxMemberValue = 60
+
+var (
+	// defaultClassAttributes are the attributes shown when no attributes are included explicitly.
+	defaultClassAttributes = []string{
+		"ami", "availability_zone", "bucket", "cidr_block", "engine", "engine_version", "handler", "image",
+		"instance_class", "instance_type", "location", "machine_type", "memory_size", "name", "node_type",
+		"region", "runtime", "size", "sku_name", "version",
+	}
+	// classTextEscaper replaces the characters Mermaid interprets in class labels and members with entity codes.
+	classTextEscaper = strings.NewReplacer(
+		"#", "#35;", `"`, "#quot;", "{", "#123;", "}", "#125;", "(", "#40;", ")", "#41;",
+		"<", "#60;", ">", "#62;", "~", "#126;", "$", "#36;", "*", "#42;",
+	)
+)
+
+func init() {
+	registerRenderer(ChartTypeClass, classRenderer{})
+}
+
+// classRenderer renders the resources of a graph as a Mermaid class diagram.
+type classRenderer struct{}
+
+func (classRenderer) Description() string {
+	return "Mermaid class diagram of resources with their planned attribute values"
+}
+
+// Render emits one class per resource whose members are the resource's attribute values selected by
+// opts.IncludeAttributes and opts.ExcludeAttributes, annotated with the planned action, and an association for
+// each dependency between resources.
+func (classRenderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	if !validDirections[opts.Direction] {
+		return "", fmt.Errorf("%w %s: valid options are TB, TD, BT, RL, LR", errInvalidDirection, opts.Direction)
+	}
+	direction := opts.Direction
+	if direction == "TD" {
+		direction = "TB"
+	}
+
+	resources := collectResources(graph, normalizeFilter(opts.Filter), opts.Verbose)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	include := opts.IncludeAttributes
+	if len(include) == 0 {
+		include = defaultClassAttributes
+	}
+
+	var sb strings.Builder
+	sb.WriteString("```mermaid\nclassDiagram\n")
+	fmt.Fprintf(&sb, "    direction %s\n", direction)
+	for _, n := range resources.nodes {
+		members := classMembers(n, include, opts.ExcludeAttributes)
+		fmt.Fprintf(&sb, "    class %s[\"%s\"]", resources.ids[n.ID], classTextEscaper.Replace(n.Label))
+		if len(members) == 0 && n.Action == "" {
+			sb.WriteString("\n")
+			continue
+		}
+		sb.WriteString(" {\n")
+		if n.Action != "" {
+			fmt.Fprintf(&sb, "        <<%s>>\n", n.Action)
+		}
+		for _, member := range members {
+			fmt.Fprintf(&sb, "        %s\n", member)
+		}
+		sb.WriteString("    }\n")
+	}
+	deps := resources.dependencies(graph)
+	for _, dep := range deps {
+		fmt.Fprintf(&sb, "    %s --> %s\n", dep[0], dep[1])
+	}
+	sb.WriteString("```\n")
+
+	if opts.Verbose {
+		utils.LogVerbose("Class diagram generation complete with %d classes and %d associations", len(resources.nodes), len(deps))
+	}
+
+	return sb.String(), nil
+}
+
+// classMembers returns the "name = value" members of n for the non-null attributes matching an include pattern
+// and no exclude pattern, sorted by name.
+func classMembers(n *model.Node, include, exclude []string) []string {
+	names := make([]string, 0, len(n.Attributes))
+	for name, value := range n.Attributes {
+		if value != nil && matchesAnyPattern(name, include) && !matchesAnyPattern(name, exclude) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	members := make([]string, len(names))
+	for i, name := range names {
+		members[i] = classTextEscaper.Replace(name + " = " + formatAttribute(n.Attributes[name]))
+	}
+	return members
+}
+
+// formatAttribute formats an attribute value as written in Terraform: strings are quoted, collections are
+// JSON-encoded, masked values are shown as is and long values are truncated.
+func formatAttribute(value any) string {
+	var s string
+	switch v := value.(type) {
+	case string:
+		if v == model.SensitiveValue {
+			return v
+		}
+		s = strconv.Quote(v)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		s = string(b)
+	}
+
+	if runes := []rune(s); len(runes) > maxMemberValue {
+		return string(runes[:maxMemberValue]) + "..."
+	}
+	return s
+}
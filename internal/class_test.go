@@ -0,0 +1,121 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const classPlan = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "aws_vpc.main", "mode": "managed", "type": "aws_vpc", "name": "main",
+      "change": {"actions": ["create"], "after": {"cidr_block": "10.0.0.0/16", "tags": {"Name": "main"}}}
+    },
+    {
+      "address": "aws_db_instance.db", "mode": "managed", "type": "aws_db_instance", "name": "db",
+      "change": {
+        "actions": ["update"],
+        "before": {},
+        "after": {"engine": "postgres", "engine_version": "16.3", "password": "hunter2", "port": 5432, "name": null},
+        "after_sensitive": {"password": true}
+      }
+    }
+  ],
+  "configuration": {
+    "root_module": {
+      "resources": [
+        {"address": "aws_vpc.main", "mode": "managed", "type": "aws_vpc", "name": "main"},
+        {
+          "address": "aws_db_instance.db", "mode": "managed", "type": "aws_db_instance", "name": "db",
+          "expressions": {"vpc_id": {"references": ["aws_vpc.main.id", "aws_vpc.main"]}}
+        }
+      ]
+    }
+  }
+}`
+
+func TestClassRenderer(t *testing.T) {
+	var plan tfjson.Plan
+	if err := json.Unmarshal([]byte(classPlan), &plan); err != nil {
+		t.Fatal(err)
+	}
+	graph := model.FromPlan(&plan)
+
+	tests := []struct {
+		name  string
+		opts  RenderOptions
+		wants []string
+		avoid []string
+	}{
+		{
+			name: "default attributes",
+			opts: RenderOptions{Direction: "TD"},
+			wants: []string{
+				"```mermaid\nclassDiagram\n    direction TB\n",
+				"    class aws_vpc_main[\"aws_vpc.main\"] {\n        <<create>>\n        cidr_block = #quot;10.0.0.0/16#quot;\n    }\n",
+				"        engine = #quot;postgres#quot;\n        engine_version = #quot;16.3#quot;\n",
+				"    aws_db_instance_db --> aws_vpc_main\n",
+			},
+			avoid: []string{"password", "port", "name ="},
+		},
+		{
+			name: "all attributes",
+			opts: RenderOptions{Direction: "LR", IncludeAttributes: []string{"*"}, ExcludeAttributes: []string{"engine*"}},
+			wants: []string{
+				"direction LR\n",
+				"password = #40;sensitive value#41;\n",
+				"port = 5432\n",
+				"tags = #123;#quot;Name#quot;:#quot;main#quot;#125;\n",
+			},
+			avoid: []string{"hunter2", "engine", "name ="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagram, err := classRenderer{}.Render(context.Background(), graph, tt.opts)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(diagram, want) {
+					t.Errorf("Render() missing %q:\n%s", want, diagram)
+				}
+			}
+			for _, avoid := range tt.avoid {
+				if strings.Contains(diagram, avoid) {
+					t.Errorf("Render() contains %q:\n%s", avoid, diagram)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatAttribute(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{"t3.micro", `"t3.micro"`},
+		{float64(3), "3"},
+		{true, "true"},
+		{[]any{"a", "b"}, `["a","b"]`},
+		{model.SensitiveValue, model.SensitiveValue},
+		{strings.Repeat("x", 70), `"` + strings.Repeat("x", 59) + "..."},
+	}
+
+	for _, tt := range tests {
+		if got := formatAttribute(tt.value); got != tt.want {
+			t.Errorf("formatAttribute(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
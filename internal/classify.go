@@ -0,0 +1,193 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// kindReasons explains why nodes of kinds other than resources and data sources are never resources.
+var kindReasons = map[model.NodeKind]string{
+	model.KindProvider: "provider configuration",
+	model.KindModule:   "module call",
+	model.KindVariable: "input variable",
+	model.KindLocal:    "local value",
+	model.KindOutput:   "output value",
+	model.KindMeta:     "not a resource address",
+}
+
+// ResourceSchema lists the resource and data source types that the configured providers define, as reported by
+// terraform providers schema -json. Each type maps to the source address of the provider that defines it.
+type ResourceSchema struct {
+	Resources   map[string]string
+	DataSources map[string]string
+}
+
+// Classification explains whether a node is drawn in the diagram.
+type Classification struct {
+	Label    string // Cleaned node label
+	Resource bool   // Whether the node is classified as a resource
+	Kept     bool   // Whether the node is drawn
+	Reason   string
+}
+
+// FetchProviderSchema runs terraform providers schema -json in opts.WorkingDir, after terraform init as described
+// by opts, and returns the resource types it defines.
+func FetchProviderSchema(ctx context.Context, opts TerraformOptions) (*ResourceSchema, error) {
+	tf, err := newTerraform(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("Reading provider schemas with terraform providers schema -json")
+	}
+
+	schemas, err := tf.ProvidersSchema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errProviderSchema, err)
+	}
+
+	return newResourceSchema(schemas), nil
+}
+
+// LoadProviderSchemaFile reads a cached copy of terraform providers schema -json output from path, or from stdin
+// if path is "-", and returns the resource types it defines.
+func LoadProviderSchemaFile(ctx context.Context, path string, verbose bool) (*ResourceSchema, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if verbose {
+		utils.LogVerbose("Reading provider schemas from %s", describeInput(path))
+	}
+
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errReadProviderSchema, err)
+	}
+
+	var schemas tfjson.ProviderSchemas
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		return nil, fmt.Errorf("%w: %w", errProviderSchema, err)
+	}
+	if len(schemas.Schemas) == 0 {
+		return nil, fmt.Errorf("%w: no provider schemas in %s", errProviderSchema, describeInput(path))
+	}
+
+	return newResourceSchema(&schemas), nil
+}
+
+func newResourceSchema(schemas *tfjson.ProviderSchemas) *ResourceSchema {
+	rs := &ResourceSchema{Resources: make(map[string]string), DataSources: make(map[string]string)}
+	for source, schema := range schemas.Schemas {
+		if schema == nil {
+			continue
+		}
+		for resourceType := range schema.ResourceSchemas {
+			rs.Resources[resourceType] = source
+		}
+		for dataType := range schema.DataSourceSchemas {
+			rs.DataSources[dataType] = source
+		}
+	}
+	return rs
+}
+
+// classifyNode reports whether n is a resource node and why. When f.Schema is nil the resource type is
+// classified with f.ResourceTypeMatcher and f.ResourceTypePrefixes, the built-in provider prefixes and finally
+// the underscore heuristic; otherwise only types defined in the schema are resources.
+func (f *FilterConfig) classifyNode(n *model.Node) (bool, string) {
+	if reason, ok := kindReasons[n.Kind]; ok {
+		return false, reason
+	}
+	typeSeg := n.Address.Type
+
+	if f.Schema != nil {
+		types, kind := f.Schema.Resources, "resource"
+		if n.Kind == model.KindData {
+			types, kind = f.Schema.DataSources, "data source"
+		}
+		if source, ok := types[typeSeg]; ok {
+			return true, fmt.Sprintf("%s type %s is defined by %s", kind, typeSeg, source)
+		}
+		return false, fmt.Sprintf("%s type %s is not in the provider schema", kind, typeSeg)
+	}
+
+	if f.ResourceTypeMatcher != nil && f.ResourceTypeMatcher.MatchString(typeSeg) {
+		return true, fmt.Sprintf("type %s matches TERRAMAID_RESOURCE_TYPE_REGEX", typeSeg)
+	}
+	for _, pref := range f.ResourceTypePrefixes {
+		if strings.HasPrefix(typeSeg, pref) {
+			return true, fmt.Sprintf("type %s has prefix %q from TERRAMAID_RESOURCE_TYPE_PREFIXES", typeSeg, pref)
+		}
+	}
+	for _, pref := range defaultResourceTypePrefixes {
+		if strings.HasPrefix(typeSeg, pref) {
+			return true, fmt.Sprintf("type %s has built-in provider prefix %q", typeSeg, pref)
+		}
+	}
+	if strings.Contains(typeSeg, "_") {
+		return true, fmt.Sprintf("type %s contains an underscore", typeSeg)
+	}
+
+	return false, fmt.Sprintf("type %s matches no resource type heuristic", typeSeg)
+}
+
+// ClassifyNodes classifies every node of graph the way GenerateMermaidFlowchart does and explains whether it is
+// drawn. Nodes are returned once per label, sorted by label.
+func ClassifyNodes(graph *model.Graph, resourcesOnly bool, filter *FilterConfig) []Classification {
+	filter = normalizeFilter(filter)
+
+	seen := make(map[string]bool)
+	var out []Classification
+	for _, node := range graph.Nodes {
+		label := node.Label
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+
+		c := Classification{Label: label, Kept: true}
+		c.Resource, c.Reason = filter.classifyNode(node)
+		switch {
+		case resourcesOnly && !c.Resource:
+			c.Kept = false
+		case !filter.Includes(node, false):
+			c.Kept = false
+			c.Reason = "excluded by the type, provider or module filters; " + c.Reason
+		}
+		out = append(out, c)
+	}
+
+	slices.SortFunc(out, func(a, b Classification) int { return strings.Compare(a.Label, b.Label) })
+	return out
+}
+
+// WriteClassificationReport writes one line per classification: whether the node is kept, its label, whether it
+// is a resource and the reason.
+func WriteClassificationReport(w io.Writer, classifications []Classification) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range classifications {
+		status, kind := "DROPPED", "other"
+		if c.Kept {
+			status = "KEPT"
+		}
+		if c.Resource {
+			kind = "resource"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status, c.Label, kind, c.Reason)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,202 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+)
+
+// classifyGraph mixes resources, data sources and meta nodes, including a resource type that no provider defines.
+const classifyGraph = `digraph G {
+  "[root] aws_instance.web (expand)" [label = "aws_instance.web"];
+  "[root] aws_instance.web (close)" [label = "aws_instance.web"];
+  "[root] data.aws_ami.ubuntu (expand)" [label = "data.aws_ami.ubuntu"];
+  "[root] mycloud_thing.x (expand)" [label = "mycloud_thing.x"];
+  "[root] random_pet.name (expand)" [label = "random_pet.name"];
+  "[root] var.region" [label = "var.region"];
+  "[root] aws_instance.web (expand)" -> "[root] data.aws_ami.ubuntu (expand)";
+  "[root] aws_instance.web (expand)" -> "[root] mycloud_thing.x (expand)";
+  "[root] aws_instance.web (expand)" -> "[root] var.region";
+}
+`
+
+func testResourceSchema(t *testing.T) *ResourceSchema {
+	t.Helper()
+	schema, err := LoadProviderSchemaFile(context.Background(), filepath.Join("testdata", "providers", "schema.json"), false)
+	if err != nil {
+		t.Fatalf("LoadProviderSchemaFile() error = %v", err)
+	}
+	return schema
+}
+
+func TestClassifyNode(t *testing.T) {
+	schema := testResourceSchema(t)
+	tests := []struct {
+		label      string
+		filter     FilterConfig
+		want       bool
+		wantReason string
+	}{
+		{label: "aws_instance.web", want: true, wantReason: `built-in provider prefix "aws"`},
+		{label: "mycloud_thing.x", want: true, wantReason: "contains an underscore"},
+		{label: "var.region", want: false, wantReason: "input variable"},
+		{label: "null.value", want: true, wantReason: `built-in provider prefix "null"`},
+		{label: "widget.x", want: false, wantReason: "matches no resource type heuristic"},
+		{label: "widget.x", filter: FilterConfig{ResourceTypeMatcher: regexp.MustCompile(`^wid`)}, want: true, wantReason: "matches TERRAMAID_RESOURCE_TYPE_REGEX"},
+		{label: "widget.x", filter: FilterConfig{ResourceTypePrefixes: []string{"widg"}}, want: true, wantReason: `has prefix "widg"`},
+		{label: "module.app", want: false, wantReason: "module call"},
+		{label: `provider["registry.terraform.io/hashicorp/aws"]`, want: false, wantReason: "provider configuration"},
+		{label: "aws_instance.web", filter: FilterConfig{Schema: schema}, want: true, wantReason: "defined by registry.terraform.io/hashicorp/aws"},
+		{label: `module.app["eu"].aws_instance.web[0]`, filter: FilterConfig{Schema: schema}, want: true, wantReason: "resource type aws_instance"},
+		{label: "data.aws_ami.ubuntu", filter: FilterConfig{Schema: schema}, want: true, wantReason: "data source type aws_ami"},
+		{label: "data.aws_instance.web", filter: FilterConfig{Schema: schema}, want: false, wantReason: "data source type aws_instance is not in the provider schema"},
+		{label: "mycloud_thing.x", filter: FilterConfig{Schema: schema}, want: false, wantReason: "not in the provider schema"},
+		{label: "output.id", filter: FilterConfig{Schema: schema}, want: false, wantReason: "output value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			got, reason := tt.filter.classifyNode(model.NewNode(tt.label))
+			if got != tt.want || !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("classifyNode(%q) = %t, %q; want %t, reason containing %q", tt.label, got, reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestLoadProviderSchemaFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(empty, []byte(`{"format_version":"1.0"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "missing", path: filepath.Join(dir, "missing.json"), wantErr: errReadProviderSchema},
+		{name: "invalid JSON", path: invalid, wantErr: errProviderSchema},
+		{name: "no providers", path: empty, wantErr: errProviderSchema},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadProviderSchemaFile(context.Background(), tt.path, false); !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadProviderSchemaFile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFetchProviderSchema(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake engine binaries are shell scripts")
+	}
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "terraform")
+	data, err := os.ReadFile(filepath.Join("testdata", "providers", "schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ncase \"$1\" in\nversion) echo '{\"terraform_version\":\"1.9.5\"}' ;;\nproviders) cat <<'EOF'\n" + string(data) + "EOF\n;;\nesac\n"
+	if err := os.WriteFile(binary, []byte(script), 0o700); err != nil { // #nosec G306 -- test binary must be executable
+		t.Fatal(err)
+	}
+
+	schema, err := FetchProviderSchema(context.Background(), TerraformOptions{WorkingDir: dir, Binary: binary, Init: InitNone})
+	if err != nil {
+		t.Fatalf("FetchProviderSchema() error = %v", err)
+	}
+	if schema.Resources["random_pet"] != "registry.terraform.io/hashicorp/random" || schema.DataSources["aws_ami"] == "" {
+		t.Errorf("FetchProviderSchema() = %+v", schema)
+	}
+}
+
+func TestClassifyNodes(t *testing.T) {
+	graph, err := parseGraph(classifyGraph, false)
+	if err != nil {
+		t.Fatalf("parseGraph() error = %v", err)
+	}
+
+	filter := &FilterConfig{ExcludeTypes: []string{"random_*"}, Schema: testResourceSchema(t)}
+	got := ClassifyNodes(graph, true, filter)
+
+	want := []struct {
+		label          string
+		resource, kept bool
+	}{
+		{"aws_instance.web", true, true},
+		{"data.aws_ami.ubuntu", true, true},
+		{"mycloud_thing.x", false, false},
+		{"random_pet.name", true, false},
+		{"var.region", false, false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ClassifyNodes() returned %d nodes, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Label != w.label || got[i].Resource != w.resource || got[i].Kept != w.kept {
+			t.Errorf("ClassifyNodes()[%d] = %+v, want %s resource=%t kept=%t", i, got[i], w.label, w.resource, w.kept)
+		}
+	}
+	if !strings.HasPrefix(got[3].Reason, "excluded by") {
+		t.Errorf("filtered node reason = %q, want the filter named", got[3].Reason)
+	}
+
+	var sb strings.Builder
+	if err := WriteClassificationReport(&sb, got); err != nil {
+		t.Fatalf("WriteClassificationReport() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if len(lines) != len(want) || !strings.HasPrefix(lines[0], "KEPT ") || !strings.HasPrefix(lines[2], "DROPPED ") {
+		t.Errorf("WriteClassificationReport() =\n%s", sb.String())
+	}
+}
+
+func TestGenerateMermaidFlowchart_ProviderSchema(t *testing.T) {
+	graph, err := parseGraph(classifyGraph, false)
+	if err != nil {
+		t.Fatalf("parseGraph() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter *FilterConfig
+		want   bool // whether mycloud_thing.x is drawn
+	}{
+		{name: "heuristics", filter: nil, want: true},
+		{name: "provider schema", filter: &FilterConfig{Schema: testResourceSchema(t)}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagram, err := GenerateMermaidFlowchart(context.Background(), graph, "TD", "", true, tt.filter, false)
+			if err != nil {
+				t.Fatalf("GenerateMermaidFlowchart() error = %v", err)
+			}
+			if got := strings.Contains(diagram, "mycloud_thing.x"); got != tt.want {
+				t.Errorf("diagram contains mycloud_thing.x = %t, want %t:\n%s", got, tt.want, diagram)
+			}
+			if !strings.Contains(diagram, "data.aws_ami.ubuntu") || strings.Contains(diagram, "var.region") {
+				t.Errorf("unexpected resource classification:\n%s", diagram)
+			}
+		})
+	}
+}
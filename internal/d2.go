@@ -0,0 +1,263 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// FormatD2 is the output format of D2 diagrams.
+const FormatD2 = "d2"
+
+var (
+	// d2UnquotedKey matches the keys D2 accepts without quotes.
+	d2UnquotedKey = regexp.MustCompile(`^\w+$`)
+	// d2Keywords are the reserved D2 keywords that must be quoted to be used as keys.
+	d2Keywords = map[string]bool{
+		"label": true, "shape": true, "icon": true, "style": true, "near": true, "tooltip": true, "link": true,
+		"width": true, "height": true, "top": true, "left": true, "direction": true, "constraint": true,
+		"class": true, "classes": true, "vars": true, "layers": true, "scenarios": true, "steps": true,
+	}
+	// d2TextEscaper escapes the characters D2 interprets in double-quoted strings.
+	d2TextEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\r\n", `\n`, "\n", `\n`)
+	// d2Directions maps diagram directions to D2 directions.
+	d2Directions = map[string]string{"TB": "down", "TD": "down", "BT": "up", "LR": "right", "RL": "left"}
+	// d2Shapes maps node kinds to the D2 shape that draws them; other kinds are rectangles.
+	d2Shapes = map[model.NodeKind]string{
+		model.KindData:     "page",
+		model.KindProvider: "hexagon",
+		model.KindModule:   "package",
+		model.KindVariable: "oval",
+		model.KindLocal:    "oval",
+		model.KindOutput:   "oval",
+		model.KindMeta:     "circle",
+	}
+	// d2StyleKeys maps the Mermaid style properties of actionClassDefs to D2 style keywords.
+	d2StyleKeys = map[string]string{
+		"fill":             "style.fill",
+		"stroke":           "style.stroke",
+		"color":            "style.font-color",
+		"stroke-dasharray": "style.stroke-dash",
+	}
+)
+
+func init() {
+	registerFormat(FormatD2, ".d2", d2Renderer{})
+}
+
+// d2Renderer renders graphs as D2 diagrams.
+type d2Renderer struct{}
+
+func (d2Renderer) Description() string {
+	return "D2 diagram with nested containers for modules or providers"
+}
+
+// Render draws the selected nodes as D2 shapes nested in a container per module or provider; a module call is
+// itself the container of its module.
+func (d2Renderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	direction, ok := d2Directions[opts.Direction]
+	if !ok {
+		return "", fmt.Errorf("%w %s: valid options are TB, TD, BT, RL, LR", errInvalidDirection, opts.Direction)
+	}
+	groupBy, err := validateGroupBy(opts.GroupBy)
+	if err != nil {
+		return "", err
+	}
+
+	state, filter, err := selectFlowchart(ctx, graph, opts)
+	if err != nil {
+		return "", err
+	}
+
+	root := &d2Container{}
+	if opts.SubgraphName != "" {
+		root = &d2Container{path: d2Key(CleanID(opts.SubgraphName)), label: opts.SubgraphName}
+	}
+	actions := make(map[string]bool)
+	for _, n := range state.nodes {
+		path := containerPath(n.node, groupBy, filter)
+		container := root
+		if path != "" {
+			container = root.child(groupBy, path)
+		}
+		container.nodes = append(container.nodes, n)
+		if n.node.Action != "" {
+			actions[n.node.Action] = true
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "direction: %s\n", direction)
+	if opts.Title != "" {
+		fmt.Fprintf(&sb, "title: %s {\n  shape: text\n  near: top-center\n  style.font-size: 24\n}\n", d2String(opts.Title))
+	}
+	appendD2Classes(&sb, actions)
+
+	paths := make(map[string]string, len(state.nodes))
+	if opts.SubgraphName != "" {
+		fmt.Fprintf(&sb, "%s: %s {\n", root.path, d2String(root.label))
+		root.write(&sb, "  ", paths)
+		sb.WriteString("}\n")
+	} else {
+		root.write(&sb, "", paths)
+	}
+
+	for _, e := range state.edges {
+		fmt.Fprintf(&sb, "%s -> %s", paths[e.from], paths[e.to])
+		if e.color != "" {
+			fmt.Fprintf(&sb, ": {style.stroke: %s}", d2String(e.color))
+		}
+		sb.WriteString("\n")
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("D2 diagram generation complete with %d nodes and %d edges", len(state.nodes), len(state.edges))
+	}
+
+	return sb.String(), nil
+}
+
+// d2Container is a container of a D2 diagram: a module, a provider when grouping by provider, or the diagram's
+// subgraph. The root container of a diagram without a subgraph has an empty path.
+type d2Container struct {
+	path     string // Dot-joined keys of the container and its parents
+	label    string
+	nodes    []selectedNode
+	children map[string]*d2Container
+}
+
+// child returns the container for the dot-joined module path below c, creating it and its parents as needed.
+// Module containers have the key of their module call, so the call is drawn as the container. Provider names
+// are never split.
+func (c *d2Container) child(groupBy, path string) *d2Container {
+	type level struct{ id, label string }
+	var levels []level
+	if groupBy == GroupByModule {
+		names := strings.Split(path, ".")
+		for i := range names {
+			address := "module." + strings.Join(names[:i+1], ".module.")
+			levels = append(levels, level{id: CleanID(address), label: address})
+		}
+	} else {
+		levels = []level{{id: "provider_" + CleanID(path), label: path}}
+	}
+
+	container := c
+	for _, l := range levels {
+		if container.children == nil {
+			container.children = make(map[string]*d2Container)
+		}
+		next, ok := container.children[l.id]
+		if !ok {
+			next = &d2Container{path: d2Path(container.path, d2Key(l.id)), label: l.label}
+			container.children[l.id] = next
+		}
+		container = next
+	}
+	return container
+}
+
+// write writes the nodes of c followed by its remaining child containers, sorted by key, and records the path of
+// every node in paths. A node whose key is the key of a child container is written as that container.
+func (c *d2Container) write(sb *strings.Builder, indent string, paths map[string]string) {
+	written := make(map[string]bool)
+	for _, n := range c.nodes {
+		key := d2Key(n.id)
+		paths[n.id] = d2Path(c.path, key)
+		fmt.Fprintf(sb, "%s%s: %s", indent, key, d2String(model.CleanLabel(n.label)))
+
+		var properties []string
+		if shape, ok := d2Shapes[n.node.Kind]; ok {
+			properties = append(properties, "shape: "+shape)
+		}
+		if n.node.Action != "" {
+			properties = append(properties, "class: "+d2Key(n.node.Action))
+		}
+
+		child, ok := c.children[n.id]
+		if !ok {
+			if len(properties) > 0 {
+				fmt.Fprintf(sb, " {%s}", strings.Join(properties, "; "))
+			}
+			sb.WriteString("\n")
+			continue
+		}
+		written[n.id] = true
+		sb.WriteString(" {\n")
+		for _, property := range properties {
+			fmt.Fprintf(sb, "%s  %s\n", indent, property)
+		}
+		child.write(sb, indent+"  ", paths)
+		fmt.Fprintf(sb, "%s}\n", indent)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(c.children)) {
+		if written[key] {
+			continue
+		}
+		child := c.children[key]
+		fmt.Fprintf(sb, "%s%s: %s {\n", indent, d2Key(key), d2String(child.label))
+		child.write(sb, indent+"  ", paths)
+		fmt.Fprintf(sb, "%s}\n", indent)
+	}
+}
+
+// appendD2Classes defines a D2 class for each plan action in actions with the colors of actionClassDefs.
+func appendD2Classes(sb *strings.Builder, actions map[string]bool) {
+	if len(actions) == 0 {
+		return
+	}
+	sb.WriteString("classes: {\n")
+	for _, def := range actionClassDefs {
+		if !actions[def.action] {
+			continue
+		}
+		fmt.Fprintf(sb, "  %s: {\n", d2Key(def.action))
+		for _, property := range actionStyle(def.action) {
+			name, value := property[0], property[1]
+			key, ok := d2StyleKeys[name]
+			if !ok {
+				continue
+			}
+			if name == "stroke-dasharray" {
+				// D2 dashes are a single gap size.
+				value, _, _ = strings.Cut(value, " ")
+				fmt.Fprintf(sb, "    %s: %s\n", key, value)
+				continue
+			}
+			fmt.Fprintf(sb, "    %s: %s\n", key, d2String(value))
+		}
+		sb.WriteString("  }\n")
+	}
+	sb.WriteString("}\n")
+}
+
+// d2Key returns id as a D2 key, quoting it when it is not a plain identifier or is a reserved keyword.
+func d2Key(id string) string {
+	if d2UnquotedKey.MatchString(id) && !d2Keywords[strings.ToLower(id)] {
+		return id
+	}
+	return d2String(id)
+}
+
+// d2String returns s as a double-quoted D2 string.
+func d2String(s string) string {
+	return `"` + d2TextEscaper.Replace(s) + `"`
+}
+
+// d2Path joins a container path and a key.
+func d2Path(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,86 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestD2Renderer(t *testing.T) {
+	graph, err := ParseStatic(context.Background(), "testdata/static", false)
+	if err != nil {
+		t.Fatalf("ParseStatic() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		opts  RenderOptions
+		wants []string
+		avoid []string
+	}{
+		{
+			name: "module containers",
+			opts: RenderOptions{Direction: "TD", SubgraphName: "Terraform", Title: `Workspace: "dev"`},
+			wants: []string{
+				"direction: down\ntitle: \"Workspace: \\\"dev\\\"\" {\n",
+				"Terraform: \"Terraform\" {\n",
+				"  var_cidr_block: \"var.cidr_block\" {shape: oval}\n",
+				"  aws_vpc_main: \"aws_vpc.main\"\n",
+				"  module_app: \"module.app\" {\n    shape: package\n    module_app_var_subnet_id: \"module.app.var.subnet_id\" {shape: oval}\n",
+				"Terraform.aws_subnet_private -> Terraform.aws_vpc_main\n",
+				"Terraform.output_web_ip -> Terraform.module_app.module_app_output_public_ip\n",
+			},
+		},
+		{
+			name: "provider containers",
+			opts: RenderOptions{Direction: "RL", ResourcesOnly: true, GroupBy: GroupByProvider},
+			wants: []string{
+				"direction: left\nprovider_aws: \"aws\" {\n",
+				"  module_app_aws_instance_web: \"module.app.aws_instance.web\"\n",
+				"provider_aws.aws_subnet_private -> provider_aws.aws_vpc_main\n",
+			},
+			avoid: []string{"var_cidr_block", "Terraform", "module_app {"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagram, err := d2Renderer{}.Render(context.Background(), graph, tt.opts)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(diagram, want) {
+					t.Errorf("Render() missing %q:\n%s", want, diagram)
+				}
+			}
+			for _, avoid := range tt.avoid {
+				if strings.Contains(diagram, avoid) {
+					t.Errorf("Render() contains %q:\n%s", avoid, diagram)
+				}
+			}
+		})
+	}
+}
+
+func TestD2Key(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"aws_vpc_main", "aws_vpc_main"},
+		{"no-op", `"no-op"`},
+		{"Label", `"Label"`},
+		{`a"b\c`, `"a\"b\\c"`},
+		{"${var}", `"\${var}"`},
+	}
+
+	for _, tt := range tests {
+		if got := d2Key(tt.id); got != tt.want {
+			t.Errorf("d2Key(%q) = %s, want %s", tt.id, got, tt.want)
+		}
+	}
+}
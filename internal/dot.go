@@ -0,0 +1,259 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	"github.com/awalterschulze/gographviz"
+)
+
+// FormatDOT is the output format of Graphviz DOT graphs.
+const FormatDOT = "dot"
+
+var (
+	// dotUnquotedID matches the IDs DOT accepts without quotes.
+	dotUnquotedID = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+	// dotKeywords are the DOT keywords, which must be quoted to be used as IDs.
+	dotKeywords = map[string]bool{"node": true, "edge": true, "graph": true, "digraph": true, "subgraph": true, "strict": true}
+	// dotTextEscaper escapes the characters DOT interprets in double-quoted strings.
+	dotTextEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r\n", `\n`, "\n", `\n`)
+	// dotShapes maps node kinds to the Graphviz shape that draws them; other kinds are boxes.
+	dotShapes = map[model.NodeKind]string{
+		model.KindData:     "note",
+		model.KindProvider: "component",
+		model.KindModule:   "folder",
+		model.KindVariable: "ellipse",
+		model.KindLocal:    "ellipse",
+		model.KindOutput:   "ellipse",
+		model.KindMeta:     "plaintext",
+	}
+	// dotStyleAttrs maps the Mermaid style properties of actionClassDefs to Graphviz node attributes.
+	dotStyleAttrs = map[string]string{"fill": "fillcolor", "stroke": "color", "color": "fontcolor"}
+)
+
+func init() {
+	registerFormat(FormatDOT, ".dot", dotRenderer{})
+}
+
+// dotRenderer renders graphs as Graphviz DOT graphs.
+type dotRenderer struct{}
+
+func (dotRenderer) Description() string {
+	return "Graphviz DOT graph with clusters for modules or providers"
+}
+
+// Render re-emits the selected graph as a DOT digraph with cleaned labels, clustering nodes by module or by
+// provider.
+func (dotRenderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	if !validDirections[opts.Direction] {
+		return "", fmt.Errorf("%w %s: valid options are TB, TD, BT, RL, LR", errInvalidDirection, opts.Direction)
+	}
+	groupBy, err := validateGroupBy(opts.GroupBy)
+	if err != nil {
+		return "", err
+	}
+
+	state, filter, err := selectFlowchart(ctx, graph, opts)
+	if err != nil {
+		return "", err
+	}
+
+	d := dotGraph{Graph: gographviz.NewGraph(), clusters: make(map[string]bool), groupBy: groupBy}
+	if err := d.build(state, filter, opts); err != nil {
+		return "", fmt.Errorf("%w: %w", errWriteDOT, err)
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("DOT graph generation complete with %d nodes and %d edges", len(state.nodes), len(state.edges))
+	}
+
+	return d.write(), nil
+}
+
+// dotGraph is a DOT graph being built from the nodes and edges of a flowchart.
+type dotGraph struct {
+	*gographviz.Graph
+	clusters map[string]bool // Names of the clusters added so far
+	groupBy  string
+	root     string // Name of the graph or cluster holding the nodes that are not grouped
+}
+
+func (d *dotGraph) build(state flowchartState, filter *FilterConfig, opts RenderOptions) error {
+	direction := opts.Direction
+	if direction == "TD" {
+		direction = "TB"
+	}
+	d.root = "terramaid"
+	if err := d.SetName(d.root); err != nil {
+		return err
+	}
+	if err := d.SetDir(true); err != nil {
+		return err
+	}
+	attrs := map[string]string{"rankdir": direction}
+	if opts.Title != "" {
+		attrs["label"] = dotString(opts.Title)
+		attrs["labelloc"] = "t"
+	}
+	for field, value := range attrs {
+		if err := d.AddAttr(d.root, field, value); err != nil {
+			return err
+		}
+	}
+	if opts.SubgraphName != "" {
+		name := dotID("cluster_" + CleanID(opts.SubgraphName))
+		if err := d.AddSubGraph(d.root, name, map[string]string{"label": dotString(opts.SubgraphName)}); err != nil {
+			return err
+		}
+		d.root = name
+	}
+
+	for _, n := range state.nodes {
+		parent, err := d.cluster(containerPath(n.node, d.groupBy, filter))
+		if err != nil {
+			return err
+		}
+		if err := d.AddNode(parent, dotID(n.id), dotNodeAttrs(n)); err != nil {
+			return err
+		}
+	}
+	for _, e := range state.edges {
+		var attrs map[string]string
+		if e.color != "" {
+			attrs = map[string]string{"color": dotString(e.color)}
+		}
+		if err := d.AddEdge(dotID(e.from), dotID(e.to), true, attrs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write returns the graph as DOT source. gographviz writes edges before the nodes and clusters they connect and
+// ends every subgraph with a stray ";", so clusters and nodes are written here first, then the edges in the order
+// they were added.
+func (d *dotGraph) write() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", d.Name)
+	writeDOTAttrs(&sb, "\t", d.Attrs)
+	d.writeChildren(&sb, d.Name, "\t")
+	for _, e := range d.Edges.Edges {
+		fmt.Fprintf(&sb, "\t%s -> %s%s;\n", e.Src, e.Dst, dotAttrList(e.Attrs))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// writeChildren writes the nodes of the graph or cluster parent followed by its clusters, each sorted by name.
+func (d *dotGraph) writeChildren(sb *strings.Builder, parent, indent string) {
+	children := d.Relations.SortedChildren(parent)
+	for _, child := range children {
+		if node, ok := d.Nodes.Lookup[child]; ok {
+			fmt.Fprintf(sb, "%s%s%s;\n", indent, child, dotAttrList(node.Attrs))
+		}
+	}
+	for _, child := range children {
+		sub, ok := d.SubGraphs.SubGraphs[child]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(sb, "%ssubgraph %s {\n", indent, child)
+		writeDOTAttrs(sb, indent+"\t", sub.Attrs)
+		d.writeChildren(sb, child, indent+"\t")
+		fmt.Fprintf(sb, "%s}\n", indent)
+	}
+}
+
+// writeDOTAttrs writes attrs as graph attribute statements, sorted by name.
+func writeDOTAttrs(sb *strings.Builder, indent string, attrs gographviz.Attrs) {
+	for _, name := range slices.Sorted(maps.Keys(attrs)) {
+		fmt.Fprintf(sb, "%s%s=%s;\n", indent, name, attrs[name])
+	}
+}
+
+// dotAttrList returns attrs as a DOT attribute list sorted by name, or "" when there are none.
+func dotAttrList(attrs gographviz.Attrs) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	list := make([]string, 0, len(attrs))
+	for _, name := range slices.Sorted(maps.Keys(attrs)) {
+		list = append(list, fmt.Sprintf("%s=%s", name, attrs[name]))
+	}
+	return " [" + strings.Join(list, ", ") + "]"
+}
+
+// cluster returns the name of the cluster for the dot-joined module path, or provider when grouping by provider,
+// adding it and its parents as needed. An empty path is the root.
+func (d *dotGraph) cluster(path string) (string, error) {
+	if path == "" {
+		return d.root, nil
+	}
+	type level struct{ name, label string }
+	var levels []level
+	if d.groupBy == GroupByModule {
+		names := strings.Split(path, ".")
+		for i := range names {
+			address := "module." + strings.Join(names[:i+1], ".module.")
+			levels = append(levels, level{name: "cluster_" + CleanID(address), label: address})
+		}
+	} else {
+		levels = []level{{name: "cluster_provider_" + CleanID(path), label: path}}
+	}
+
+	parent := d.root
+	for _, l := range levels {
+		name := dotID(l.name)
+		if !d.clusters[name] {
+			if err := d.AddSubGraph(parent, name, map[string]string{"label": dotString(l.label)}); err != nil {
+				return "", err
+			}
+			d.clusters[name] = true
+		}
+		parent = name
+	}
+	return parent, nil
+}
+
+// dotNodeAttrs returns the label, shape and plan action colors of n.
+func dotNodeAttrs(n selectedNode) map[string]string {
+	attrs := map[string]string{"label": dotString(model.CleanLabel(n.label)), "shape": "box"}
+	if shape, ok := dotShapes[n.node.Kind]; ok {
+		attrs["shape"] = shape
+	}
+	style := actionStyle(n.node.Action)
+	if style == nil {
+		return attrs
+	}
+	attrs["style"] = "filled"
+	for _, property := range style {
+		if property[0] == "stroke-dasharray" {
+			attrs["style"] = dotString("filled,dashed")
+		} else if attr, ok := dotStyleAttrs[property[0]]; ok {
+			attrs[attr] = dotString(property[1])
+		}
+	}
+	return attrs
+}
+
+// dotID returns id as a DOT ID, quoting it when it is not a plain identifier or is a keyword.
+func dotID(id string) string {
+	if dotUnquotedID.MatchString(id) && !dotKeywords[strings.ToLower(id)] {
+		return id
+	}
+	return dotString(id)
+}
+
+// dotString returns s as a double-quoted DOT string.
+func dotString(s string) string {
+	return `"` + dotTextEscaper.Replace(s) + `"`
+}
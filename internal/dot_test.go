@@ -0,0 +1,156 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+func TestDOTRenderer(t *testing.T) {
+	graph, err := ParseStatic(context.Background(), "testdata/static", false)
+	if err != nil {
+		t.Fatalf("ParseStatic() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     RenderOptions
+		children map[string][]string // Nodes or clusters expected in each graph or cluster
+		labels   map[string]string
+		absent   []string
+	}{
+		{
+			name: "module clusters",
+			opts: RenderOptions{Direction: "TD", SubgraphName: "Terraform", Title: `Workspace: "dev"`},
+			children: map[string][]string{
+				"terramaid":         {"cluster_Terraform"},
+				"cluster_Terraform": {"aws_vpc_main", "module_app", "cluster_module_app"},
+				"cluster_module_app": {
+					"module_app_aws_instance_web", "module_app_var_subnet_id",
+				},
+			},
+			labels: map[string]string{
+				"aws_vpc_main":                `"aws_vpc.main"`,
+				"module_app_aws_instance_web": `"module.app.aws_instance.web"`,
+				"cluster_module_app":          `"module.app"`,
+				"terramaid":                   `"Workspace: \"dev\""`,
+			},
+		},
+		{
+			name: "provider clusters",
+			opts: RenderOptions{Direction: "LR", ResourcesOnly: true, GroupBy: GroupByProvider},
+			children: map[string][]string{
+				"cluster_provider_aws": {"aws_vpc_main", "module_app_aws_instance_web"},
+			},
+			absent: []string{"var_cidr_block", "cluster_module_app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagram, err := dotRenderer{}.Render(context.Background(), graph, tt.opts)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			ast, err := gographviz.ParseString(diagram)
+			if err != nil {
+				t.Fatalf("Render() wrote invalid DOT: %v\n%s", err, diagram)
+			}
+			dot := gographviz.NewGraph()
+			if err := gographviz.Analyse(ast, dot); err != nil {
+				t.Fatalf("Render() wrote invalid DOT: %v\n%s", err, diagram)
+			}
+
+			for parent, children := range tt.children {
+				for _, child := range children {
+					if !dot.Relations.ParentToChildren[parent][child] {
+						t.Errorf("%s not in %s:\n%s", child, parent, diagram)
+					}
+				}
+			}
+			for name, want := range tt.labels {
+				var got string
+				switch {
+				case dot.IsNode(name):
+					got = dot.Nodes.Lookup[name].Attrs["label"]
+				case dot.IsSubGraph(name):
+					got = dot.SubGraphs.SubGraphs[name].Attrs["label"]
+				default:
+					got = dot.Attrs["label"]
+				}
+				if got != want {
+					t.Errorf("label of %s = %s, want %s", name, got, want)
+				}
+			}
+			for _, name := range tt.absent {
+				if dot.IsNode(name) || dot.IsSubGraph(name) {
+					t.Errorf("Render() contains %s:\n%s", name, diagram)
+				}
+			}
+		})
+	}
+}
+
+func TestDOTRenderer_Layout(t *testing.T) {
+	graph, err := ParseStatic(context.Background(), "testdata/static", false)
+	if err != nil {
+		t.Fatalf("ParseStatic() error = %v", err)
+	}
+	diagram, err := dotRenderer{}.Render(context.Background(), graph, RenderOptions{Direction: "TD", SubgraphName: "Terraform"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	ast, err := gographviz.ParseString(diagram)
+	if err != nil {
+		t.Fatalf("Render() wrote invalid DOT: %v\n%s", err, diagram)
+	}
+	dot := gographviz.NewGraph()
+	if err := gographviz.Analyse(ast, dot); err != nil {
+		t.Fatalf("Render() wrote invalid DOT: %v\n%s", err, diagram)
+	}
+	if len(dot.Edges.Edges) == 0 || len(dot.Nodes.Nodes) == 0 {
+		t.Fatalf("Render() parsed back to %d nodes and %d edges:\n%s", len(dot.Nodes.Nodes), len(dot.Edges.Edges), diagram)
+	}
+
+	lastDeclaration, firstEdge := -1, -1
+	for i, line := range strings.Split(diagram, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == ";":
+			t.Errorf("line %d is a stray separator:\n%s", i+1, diagram)
+		case strings.Contains(line, " -> "):
+			if firstEdge < 0 {
+				firstEdge = i
+			}
+		case strings.HasPrefix(line, "subgraph "), strings.Contains(line, "[label="):
+			lastDeclaration = i
+		}
+	}
+	if firstEdge < lastDeclaration {
+		t.Errorf("edge on line %d precedes the declaration on line %d:\n%s", firstEdge+1, lastDeclaration+1, diagram)
+	}
+}
+
+func TestDOTID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"aws_vpc_main", "aws_vpc_main"},
+		{"Node", `"Node"`},
+		{"1st", `"1st"`},
+		{`a"b\c`, `"a\"b\\c"`},
+	}
+
+	for _, tt := range tests {
+		if got := dotID(tt.id); got != tt.want {
+			t.Errorf("dotID(%q) = %s, want %s", tt.id, got, tt.want)
+		}
+	}
+}
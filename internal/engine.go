@@ -0,0 +1,78 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+)
+
+// Supported infrastructure-as-code engines.
+const (
+	EngineTerraform = "terraform"
+	EngineTofu      = "tofu"
+	EngineAuto      = "auto"
+)
+
+// engineVersionLine matches the first line of `terraform version` and `tofu version` output.
+var engineVersionLine = regexp.MustCompile(`^(Terraform|OpenTofu) v(\S+)`)
+
+// Engine describes the Terraform-compatible binary used to build graphs.
+type Engine struct {
+	Name    string // EngineTerraform or EngineTofu
+	Version string // Version reported by the binary, without the leading "v"
+}
+
+// FindEngineBinary locates the binary for engine on PATH. With EngineAuto it prefers terraform and falls
+// back to tofu when Terraform is not installed.
+func FindEngineBinary(engine string) (string, error) {
+	var candidates []string
+	switch engine {
+	case EngineTerraform:
+		candidates = []string{EngineTerraform}
+	case EngineTofu:
+		candidates = []string{EngineTofu}
+	case EngineAuto, "":
+		candidates = []string{EngineTerraform, EngineTofu}
+	default:
+		return "", fmt.Errorf("%w %q: valid options are %s, %s, %s", errInvalidEngine, engine, EngineTerraform, EngineTofu, EngineAuto)
+	}
+
+	var lastErr error
+	for _, name := range candidates {
+		path, err := exec.LookPath(name)
+		if err == nil {
+			return path, nil
+		}
+		lastErr = err
+	}
+
+	return "", lastErr
+}
+
+// DetectEngine runs `<binary> version` and reports whether the binary is Terraform or OpenTofu and its version.
+func DetectEngine(ctx context.Context, binary string) (Engine, error) {
+	// #nosec G204 -- the binary is the user-selected Terraform or OpenTofu executable
+	out, err := exec.CommandContext(ctx, binary, "version").Output()
+	if err != nil {
+		return Engine{}, fmt.Errorf("%w: %w", errDetectEngine, err)
+	}
+
+	line, _, _ := bufio.NewReader(bytes.NewReader(out)).ReadLine()
+	match := engineVersionLine.FindSubmatch(line)
+	if match == nil {
+		return Engine{}, fmt.Errorf("%w: unrecognized version output %q", errDetectEngine, line)
+	}
+
+	engine := Engine{Name: EngineTerraform, Version: string(match[2])}
+	if string(match[1]) == "OpenTofu" {
+		engine.Name = EngineTofu
+	}
+
+	return engine, nil
+}
@@ -0,0 +1,151 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeEngineScript emulates the subset of the Terraform/OpenTofu CLI used by Terramaid.
+const fakeEngineScript = `#!/bin/sh
+case "$1" in
+version)
+  if [ "$2" = "-json" ]; then
+    echo '{"terraform_version":"%[2]s","platform":"linux_amd64","provider_selections":{}}'
+  else
+    echo "%[1]s v%[2]s"
+    echo "on linux_amd64"
+  fi
+  ;;
+init)
+  ;;
+graph)
+  cat <<'GRAPH'
+%[3]s
+GRAPH
+  ;;
+*)
+  exit 1
+  ;;
+esac
+`
+
+// writeFakeEngine writes a fake engine binary named name into dir that reports itself as product at version
+// and prints graph for `graph`.
+func writeFakeEngine(t *testing.T, dir, name, product, version, graph string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake engine binaries are shell scripts")
+	}
+
+	path := filepath.Join(dir, name)
+	script := fmt.Sprintf(fakeEngineScript, product, version, graph)
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil { // #nosec G306 -- test binary must be executable
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDetectEngine(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		product string
+		version string
+		want    Engine
+	}{
+		{name: EngineTerraform, product: "Terraform", version: "1.9.5", want: Engine{Name: EngineTerraform, Version: "1.9.5"}},
+		{name: EngineTofu, product: "OpenTofu", version: "1.8.0", want: Engine{Name: EngineTofu, Version: "1.8.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binary := writeFakeEngine(t, dir, tt.name, tt.product, tt.version, "")
+			got, err := DetectEngine(context.Background(), binary)
+			if err != nil {
+				t.Fatalf("DetectEngine() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectEngine() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectEngine_Unrecognized(t *testing.T) {
+	binary := writeFakeEngine(t, t.TempDir(), "terraform", "Pulumi", "3.0.0", "")
+	if _, err := DetectEngine(context.Background(), binary); !errors.Is(err, errDetectEngine) {
+		t.Errorf("DetectEngine() error = %v, want %v", err, errDetectEngine)
+	}
+}
+
+func TestFindEngineBinary(t *testing.T) {
+	tofuOnly := t.TempDir()
+	tofu := writeFakeEngine(t, tofuOnly, EngineTofu, "OpenTofu", "1.8.0", "")
+
+	both := t.TempDir()
+	terraform := writeFakeEngine(t, both, EngineTerraform, "Terraform", "1.9.5", "")
+	writeFakeEngine(t, both, EngineTofu, "OpenTofu", "1.8.0", "")
+
+	tests := []struct {
+		name    string
+		path    string
+		engine  string
+		want    string
+		wantErr bool
+	}{
+		{name: "auto falls back to tofu", path: tofuOnly, engine: EngineAuto, want: tofu},
+		{name: "auto prefers terraform", path: both, engine: EngineAuto, want: terraform},
+		{name: "explicit tofu", path: tofuOnly, engine: EngineTofu, want: tofu},
+		{name: "explicit terraform missing", path: tofuOnly, engine: EngineTerraform, wantErr: true},
+		{name: "invalid engine", path: both, engine: "pulumi", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", tt.path)
+			got, err := FindEngineBinary(tt.engine)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindEngineBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FindEngineBinary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTerraform_Engines(t *testing.T) {
+	tests := []struct {
+		name    string
+		binary  string
+		product string
+		graph   string
+		wantErr error
+	}{
+		{name: "terraform graph", binary: EngineTerraform, product: "Terraform", graph: sampleGraph},
+		{name: "tofu graph", binary: EngineTofu, product: "OpenTofu", graph: sampleGraph},
+		{name: "terraform empty graph", binary: EngineTerraform, product: "Terraform", graph: terraformEmptyGraph, wantErr: errNoTerraformGraphData},
+		{name: "tofu empty graph", binary: EngineTofu, product: "OpenTofu", graph: tofuEmptyGraph, wantErr: errNoTerraformGraphData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binary := writeFakeEngine(t, t.TempDir(), tt.binary, tt.product, "1.8.0", tt.graph)
+			graph, err := ParseTerraform(context.Background(), TerraformOptions{WorkingDir: t.TempDir(), Binary: binary})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseTerraform() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && len(graph.Nodes) != 2 {
+				t.Errorf("ParseTerraform() nodes = %d, want 2", len(graph.Nodes))
+			}
+		})
+	}
+}
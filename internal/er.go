@@ -0,0 +1,203 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// ChartTypeER is the chart type of entity relationship diagrams.
+const ChartTypeER = "er"
+
+// erNameUnsafe matches the characters Mermaid does not accept in entity attribute names.
+var erNameUnsafe = regexp.MustCompile(`[^\w-]+`)
+
+func init() {
+	registerRenderer(ChartTypeER, erRenderer{})
+}
+
+// erRenderer renders the resources of a graph as a Mermaid entity relationship diagram.
+type erRenderer struct{}
+
+func (erRenderer) Description() string {
+	return "Mermaid entity relationship diagram labelling dependencies with the referring and referenced attributes"
+}
+
+// relationship is a reference from an argument of one drawn resource to an attribute of another.
+type relationship struct {
+	from, to string // Cleaned IDs of the dependent and the dependency
+	ref      model.Reference
+}
+
+// Render emits one entity per resource listing the attributes that take part in references, and one
+// relationship per reference labelled with the referring argument and the referenced attribute. References
+// through variables, locals, outputs and modules are followed; their label names the argument of the dependent
+// and the attribute finally read from the dependency.
+func (erRenderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	if !validDirections[opts.Direction] {
+		return "", fmt.Errorf("%w %s: valid options are TB, TD, BT, RL, LR", errInvalidDirection, opts.Direction)
+	}
+	direction := opts.Direction
+	if direction == "TD" {
+		direction = "TB"
+	}
+
+	resources := collectResources(graph, normalizeFilter(opts.Filter), opts.Verbose)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	relationships := resources.relationships(graph)
+
+	// keys maps entities to their attributes that take part in references; true marks referring arguments.
+	keys := make(map[string]map[string]bool)
+	for _, rel := range relationships {
+		if rel.ref.From != "" {
+			addKey(keys, rel.from, rel.ref.From, true)
+		}
+		if rel.ref.To != "" {
+			addKey(keys, rel.to, rel.ref.To, false)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("```mermaid\nerDiagram\n")
+	fmt.Fprintf(&sb, "    direction %s\n", direction)
+	for _, n := range resources.nodes {
+		id := resources.ids[n.ID]
+		fmt.Fprintf(&sb, "    %s[\"%s\"]", id, strings.ReplaceAll(n.Label, `"`, "'"))
+		if len(keys[id]) == 0 {
+			sb.WriteString("\n")
+			continue
+		}
+		sb.WriteString(" {\n")
+		for _, name := range slices.Sorted(maps.Keys(keys[id])) {
+			fmt.Fprintf(&sb, "        %s %s", attributeType(n.Attributes[name]), erNameUnsafe.ReplaceAllString(name, "_"))
+			if keys[id][name] {
+				sb.WriteString(" FK")
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("    }\n")
+	}
+	for _, rel := range relationships {
+		fmt.Fprintf(&sb, "    %s }o--|| %s : \"%s\"\n", rel.from, rel.to, relationshipLabel(rel.ref))
+	}
+	sb.WriteString("```\n")
+
+	if opts.Verbose {
+		utils.LogVerbose("ER diagram generation complete with %d entities and %d relationships", len(resources.nodes), len(relationships))
+	}
+
+	return sb.String(), nil
+}
+
+// relationships returns the references between drawn resources, following edges through nodes that are not
+// drawn. A reference through such nodes combines the argument of the first edge with the attribute of the last.
+// Relationships are in graph order without duplicates or self-references.
+func (s *resourceSet) relationships(graph *model.Graph) []relationship {
+	out := make(map[string][]*model.Edge)
+	for _, e := range graph.Edges {
+		out[e.From] = append(out[e.From], e)
+	}
+
+	var rels []relationship
+	added := make(map[relationship]bool)
+	add := func(from, to string, ref model.Reference) {
+		rel := relationship{from: from, to: to, ref: ref}
+		if from != to && !added[rel] {
+			added[rel] = true
+			rels = append(rels, rel)
+		}
+	}
+
+	for _, n := range graph.Nodes {
+		from, ok := s.ids[n.ID]
+		if !ok {
+			continue
+		}
+		for _, first := range out[n.ID] {
+			arguments := edgeReferences(first)
+			visited := map[string]bool{n.ID: true}
+			queue := []*model.Edge{first}
+			for len(queue) > 0 {
+				e := queue[0]
+				queue = queue[1:]
+				if to, drawn := s.ids[e.To]; drawn {
+					if e == first {
+						for _, ref := range arguments {
+							add(from, to, ref)
+						}
+						continue
+					}
+					for _, arg := range arguments {
+						for _, attr := range edgeReferences(e) {
+							add(from, to, model.Reference{From: arg.From, To: attr.To})
+						}
+					}
+					continue
+				}
+				if !visited[e.To] {
+					visited[e.To] = true
+					queue = append(queue, out[e.To]...)
+				}
+			}
+		}
+	}
+	return rels
+}
+
+// edgeReferences returns the references of e, or a single unknown reference when none are recorded.
+func edgeReferences(e *model.Edge) []model.Reference {
+	if len(e.References) == 0 {
+		return []model.Reference{{}}
+	}
+	return e.References
+}
+
+// relationshipLabel describes a reference, e.g. "vpc_id -> id".
+func relationshipLabel(ref model.Reference) string {
+	switch {
+	case ref.From != "" && ref.To != "":
+		return ref.From + " -> " + ref.To
+	case ref.From != "":
+		return ref.From
+	case ref.To != "":
+		return "-> " + ref.To
+	default:
+		return "depends on"
+	}
+}
+
+// attributeType returns the Terraform type name of a planned attribute value, or "any" when it is unknown.
+func attributeType(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "map"
+	default:
+		return "any"
+	}
+}
+
+func addKey(keys map[string]map[string]bool, entity, name string, referring bool) {
+	if keys[entity] == nil {
+		keys[entity] = make(map[string]bool)
+	}
+	keys[entity][name] = keys[entity][name] || referring
+}
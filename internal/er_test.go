@@ -0,0 +1,84 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+)
+
+func TestERRenderer(t *testing.T) {
+	ctx := context.Background()
+	static, err := ParseStatic(ctx, "testdata/static", false)
+	if err != nil {
+		t.Fatalf("ParseStatic() error = %v", err)
+	}
+	plan, err := ParsePlanJSONFile(ctx, "testdata/plan.json", false)
+	if err != nil {
+		t.Fatalf("ParsePlanJSONFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		graph *model.Graph
+		wants []string
+	}{
+		{
+			name:  "static",
+			graph: static,
+			wants: []string{
+				"```mermaid\nerDiagram\n    direction LR\n",
+				"    aws_vpc_main[\"aws_vpc.main\"] {\n        any id\n    }\n",
+				"    aws_subnet_private[\"aws_subnet.private\"] {\n        any id\n        any vpc_id FK\n    }\n",
+				"    aws_subnet_private }o--|| aws_vpc_main : \"vpc_id -> id\"\n",
+				// module.app's subnet_id input passes aws_subnet.private[0].id on to aws_instance.web.
+				"    module_app_aws_instance_web }o--|| aws_subnet_private : \"subnet_id -> id\"\n",
+				"    module_app_aws_instance_web }o--|| data_aws_ami_ubuntu : \"ami -> id\"\n",
+			},
+		},
+		{
+			name:  "plan",
+			graph: plan,
+			wants: []string{
+				"    aws_subnet_private_0 }o--|| aws_vpc_main : \"vpc_id -> id\"\n",
+				"    aws_route53_record_web }o--|| module_app_eu_aws_instance_web : \"records -> public_ip\"\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagram, err := erRenderer{}.Render(ctx, tt.graph, RenderOptions{Direction: "LR"})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(diagram, want) {
+					t.Errorf("Render() missing %q:\n%s", want, diagram)
+				}
+			}
+		})
+	}
+}
+
+func TestRelationshipLabel(t *testing.T) {
+	tests := []struct {
+		ref  model.Reference
+		want string
+	}{
+		{model.Reference{From: "vpc_id", To: "id"}, "vpc_id -> id"},
+		{model.Reference{From: "depends_on"}, "depends_on"},
+		{model.Reference{To: "arn"}, "-> arn"},
+		{model.Reference{}, "depends on"},
+	}
+
+	for _, tt := range tests {
+		if got := relationshipLabel(tt.ref); got != tt.want {
+			t.Errorf("relationshipLabel(%+v) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,42 @@ package internal
 import "errors"
 
 var (
-	errInvalidDirection     = errors.New("invalid direction")
-	errNoTerraformGraphData = errors.New("no output from terraform graph")
+	errInvalidDirection        = errors.New("invalid direction")
+	errUnknownChartType        = errors.New("unknown chart type")
+	errUnknownFormat           = errors.New("unknown format")
+	errFormatChartType         = errors.New("chart type is only supported by the Mermaid format")
+	errNoTerraformGraphData    = errors.New("no output from terraform graph")
+	errReadGraphFile           = errors.New("error reading graph file")
+	errReadPlanFile            = errors.New("error reading plan JSON file")
+	errParsePlanJSON           = errors.New("error parsing plan JSON")
+	errNoPlanResourceChanges   = errors.New("plan contains no resource changes")
+	errReadStateFile           = errors.New("error reading state file")
+	errParseState              = errors.New("error parsing state")
+	errUnsupportedStateVersion = errors.New("unsupported state version")
+	errNoStateResources        = errors.New("state contains no resources")
+	errInvalidEngine           = errors.New("invalid engine")
+	errDetectEngine            = errors.New("error detecting engine")
+	errNoTerragruntUnits       = errors.New("no terragrunt.hcl units found in directory")
+	errParseTerragrunt         = errors.New("error parsing terragrunt.hcl")
+	errInvalidInitMode         = errors.New("invalid init mode")
+	errInvalidLockfileMode     = errors.New("invalid lockfile mode")
+	errTerraformInit           = errors.New("terraform init failed")
+	errInvalidGraphType        = errors.New("invalid graph type")
+	errWorkspaceNotFound       = errors.New("workspace does not exist")
+	errSelectWorkspace         = errors.New("error selecting workspace")
+	errRestoreWorkspace        = errors.New("error restoring workspace")
+	errInvalidVariable         = errors.New("invalid variable assignment")
+	errSpeculativePlan         = errors.New("speculative plan failed")
+	errNoRootModules           = errors.New("no root modules found")
+	errProviderSchema          = errors.New("error reading provider schemas")
+	errReadProviderSchema      = errors.New("error reading provider schema file")
+	errParseHCL                = errors.New("error parsing Terraform configuration")
+	errModuleDepthExceeded     = errors.New("maximum module nesting depth exceeded")
+	errInvalidGroupBy          = errors.New("invalid group by")
+	errInvalidIcon             = errors.New("invalid icon")
+	errReadIconMap             = errors.New("error reading icon map")
+	errParseIconMap            = errors.New("error parsing icon map")
+	errWriteDOT                = errors.New("error writing DOT graph")
+	errEncodeJSON              = errors.New("error encoding JSON graph")
+	errEncodeXML               = errors.New("error encoding XML graph")
 )
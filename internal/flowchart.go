@@ -5,25 +5,20 @@ package internal
 import (
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/RoseSecurity/terramaid/internal/model"
 	"github.com/RoseSecurity/terramaid/pkg/utils"
-	"github.com/awalterschulze/gographviz"
 )
 
 var (
-	labelCleaner = regexp.MustCompile(`\s*\(expand\)|\s*\(close\)|\[root\]\s*|"`)
 	// Regex to match all problematic characters for Mermaid IDs in one pass.
 	mermaidUnsafeChars = regexp.MustCompile(`[()\[\]{}<>\s\-:;,!@#$%^&*+=|\\?\'"` + "`" + `~]+`)
 	// Regex to match multiple consecutive underscores.
 	multipleUnderscores = regexp.MustCompile(`_+`)
-	// Optional, user-provided regex (via env TERRAMAID_RESOURCE_TYPE_REGEX) to classify resource types.
-	customResourceTypeMatcher *regexp.Regexp
-	// Optional, user-provided prefixes (via env TERRAMAID_RESOURCE_TYPE_PREFIXES, comma-separated).
-	customResourceTypePrefixes []string
 	// Built-in defaults for common/major provider resource type prefixes.
 	defaultResourceTypePrefixes = []string{
 		"aws", "azurerm", "google", "kubernetes", "helm",
@@ -32,38 +27,32 @@ var (
 		"pagerduty", "random", "null", "tls",
 	}
 	validDirections = map[string]bool{"TB": true, "TD": true, "BT": true, "RL": true, "LR": true}
-)
-
-// init initializes package-level resource type customization from environment variables.
-// It sets `customResourceTypeMatcher` from `TERRAMAID_RESOURCE_TYPE_REGEX` if the value is a valid
-// regular expression (invalid patterns are ignored). It also populates
-// `customResourceTypePrefixes` from `TERRAMAID_RESOURCE_TYPE_PREFIXES` by splitting on commas,
-// trimming whitespace, and discarding empty entries.
-func init() {
-	if pattern := os.Getenv("TERRAMAID_RESOURCE_TYPE_REGEX"); pattern != "" {
-		// Best-effort compile; if it fails, we silently ignore and proceed with defaults
-		if re, err := regexp.Compile(pattern); err == nil {
-			customResourceTypeMatcher = re
-		}
-	}
-
-	if prefixes := os.Getenv("TERRAMAID_RESOURCE_TYPE_PREFIXES"); prefixes != "" {
-		// Normalize: trim spaces and drop empties
-		for p := range strings.SplitSeq(prefixes, ",") {
-			p = strings.TrimSpace(p)
-			if p != "" {
-				customResourceTypePrefixes = append(customResourceTypePrefixes, p)
-			}
-		}
+	// Mermaid class definitions for plan actions, in the order they are emitted.
+	actionClassDefs = []struct{ action, style string }{
+		{model.ActionCreate, "fill:#d4edda,stroke:#28a745,color:#155724"},
+		{model.ActionUpdate, "fill:#fff3cd,stroke:#ffc107,color:#856404"},
+		{model.ActionReplace, "fill:#e2d9f3,stroke:#6f42c1,color:#3d2373"},
+		{model.ActionDelete, "fill:#f8d7da,stroke:#dc3545,color:#721c24"},
+		{model.ActionRead, "fill:#d1ecf1,stroke:#17a2b8,color:#0c5460"},
+		{model.ActionForget, "fill:#e2e3e5,stroke:#6c757d,color:#383d41,stroke-dasharray:5 5"},
+		{model.ActionNoOp, "fill:#f8f9fa,stroke:#adb5bd,color:#495057"},
 	}
-}
+)
 
 // FilterConfig holds the configuration for filtering resources in the diagram.
 type FilterConfig struct {
 	IncludeTypes     []string // Include only these resource types (supports glob patterns)
 	ExcludeTypes     []string // Exclude these resource types (supports glob patterns)
-	IncludeProviders []string // Include only resources from these providers
+	IncludeProviders []string // Include only resources from these providers: names, aliases (aws.west) or sources (hashicorp/aws, hashicorp/aws.west)
 	ExcludeModules   []string // Exclude resources from these modules
+	// Providers maps resource labels to their resolved provider (see ResolveProviders). Resources without an
+	// entry fall back to the provider recorded on their node, then to the provider named by their type prefix.
+	Providers map[string]ProviderRef
+	// Schema, when set, classifies a node as a resource only if the providers define its type.
+	Schema *ResourceSchema
+	// ResourceTypeMatcher and ResourceTypePrefixes classify additional resource types when Schema is nil.
+	ResourceTypeMatcher  *regexp.Regexp
+	ResourceTypePrefixes []string
 }
 
 // IsEmpty returns true if no filters are configured.
@@ -74,61 +63,19 @@ func (f *FilterConfig) IsEmpty() bool {
 		len(f.ExcludeModules) == 0
 }
 
-// parseLabelComponents extracts the module path, resource type, and provider from a Terraform label.
-// Label formats:
-// - <type>.<name> (e.g., aws_instance.web)
-// - module.<mod>.<type>.<name> (e.g., module.vpc.aws_subnet.private)
-// parseLabelComponents parses a Terraform-style node label and returns the module path,
-// resource type, and provider.
+// nodeComponents returns the module path, resource type, and provider of a node's address.
 //
-// The function recognizes labels with leading module segments (e.g. "module.foo.module.bar.resource_type.name")
-// and returns the module path as dot-joined module names ("foo.bar"). For data sources of the form
-// "data.<type>.<name>" it returns the `<type>` as the resource type. For regular resources it returns
-// the first segment as the resource type (e.g. "aws_instance"). The provider is inferred as the prefix
-// before the first underscore in the resource type (e.g. "aws" from "aws_instance").
+// Module instance keys are ignored, so `module.app["eu"].aws_instance.web[0]` has the module path "app".
+// Nested modules are dot-joined ("network.subnets"). For data sources the data source type is returned as the
+// resource type. The provider is inferred as the prefix before the first underscore in the resource type
+// (e.g. "aws" from "aws_instance").
 //
 // Returns:
-//   - modulePath: dot-joined module names extracted from leading "module" segments, or empty if none.
-//   - resourceType: resource type or data source type if determinable, otherwise empty.
+//   - modulePath: dot-joined module names, or empty if none.
+//   - resourceType: resource type or data source type, or empty for nodes that are not resources.
 //   - provider: prefix before '_' in resourceType, or empty if not present.
-func parseLabelComponents(label string) (modulePath string, resourceType string, provider string) {
-	if label == "" {
-		return "", "", ""
-	}
-
-	parts := strings.Split(label, ".")
-	if len(parts) < 2 {
-		return "", "", ""
-	}
-
-	// Collect module path segments
-	var moduleSegments []string
-	for len(parts) >= 2 && parts[0] == "module" {
-		moduleSegments = append(moduleSegments, parts[1])
-		parts = parts[2:]
-	}
-	modulePath = strings.Join(moduleSegments, ".")
-
-	if len(parts) < 2 {
-		return modulePath, "", ""
-	}
-
-	// Handle data sources: data.<type>.<name>
-	if parts[0] == "data" {
-		if len(parts) >= 3 {
-			resourceType = parts[1]
-		}
-	} else {
-		// Regular resource: <type>.<name>
-		resourceType = parts[0]
-	}
-
-	// Extract provider from resource type (e.g., "aws" from "aws_instance")
-	if idx := strings.Index(resourceType, "_"); idx > 0 {
-		provider = resourceType[:idx]
-	}
-
-	return modulePath, resourceType, provider
+func nodeComponents(n *model.Node) (modulePath string, resourceType string, provider string) {
+	return n.ModulePath(), n.Address.Type, n.Address.Provider()
 }
 
 // matchesGlobPattern reports whether s matches the glob pattern.
@@ -153,16 +100,16 @@ func matchesAnyPattern(s string, patterns []string) bool {
 	return false
 }
 
-// ShouldInclude determines if a resource label should be included based on the filter configuration.
-func (f *FilterConfig) ShouldInclude(label string, verbose bool) bool {
+// Includes determines if a node should be included based on the filter configuration.
+func (f *FilterConfig) Includes(n *model.Node, verbose bool) bool {
 	if f.IsEmpty() {
 		return true
 	}
 
-	modulePath, resourceType, provider := parseLabelComponents(label)
-	return f.includesModule(label, modulePath, verbose) &&
-		f.includesType(label, resourceType, verbose) &&
-		f.includesProvider(label, provider, verbose)
+	modulePath, resourceType, provider := nodeComponents(n)
+	return f.includesModule(n.Label, modulePath, verbose) &&
+		f.includesType(n.Label, resourceType, verbose) &&
+		f.includesProvider(n, provider, verbose)
 }
 
 func (f *FilterConfig) includesModule(label string, modulePath string, verbose bool) bool {
@@ -209,11 +156,21 @@ func (f *FilterConfig) includesType(label string, resourceType string, verbose b
 	return true
 }
 
-func (f *FilterConfig) includesProvider(label string, provider string, verbose bool) bool {
+func (f *FilterConfig) includesProvider(n *model.Node, provider string, verbose bool) bool {
 	if len(f.IncludeProviders) == 0 {
 		return true
 	}
-	if provider == "" {
+
+	label := n.Label
+	ref, resolved := f.Providers[label]
+	switch {
+	case resolved:
+	case n.Provider != "" && n.Kind != model.KindProvider:
+		ref = nodeProviderRef(n)
+	default:
+		ref = ProviderRef{LocalName: provider}
+	}
+	if ref.Source == "" && ref.LocalName == "" {
 		if verbose {
 			utils.LogVerbose("Excluding %s: no provider detected and provider filter is active", label)
 		}
@@ -221,20 +178,25 @@ func (f *FilterConfig) includesProvider(label string, provider string, verbose b
 	}
 
 	for _, includeProvider := range f.IncludeProviders {
-		if strings.EqualFold(provider, includeProvider) {
+		if ref.Matches(includeProvider) {
 			return true
 		}
 	}
 
 	if verbose {
-		utils.LogVerbose("Excluding %s: provider %s not in include list", label, provider)
+		utils.LogVerbose("Excluding %s: provider %s not in include list", label, ref)
 	}
 	return false
 }
 
 // CleanID removes inline annotations and provider wrappers from an identifier, replaces dot and path separators with underscores, and returns a sanitized Mermaid-compatible identifier.
+// Identifiers that are Terraform addresses are split with ParseAddress, so dots and quotes inside instance keys are handled.
 func CleanID(id string) string {
-	id = labelCleaner.ReplaceAllString(id, "")
+	if addr, ok := model.ParseNodeAddress(id); ok {
+		id = addr.ID()
+	} else {
+		id = model.StripAnnotations(id)
+	}
 	if strings.HasPrefix(id, "provider[") {
 		id = strings.ReplaceAll(id, "provider[", "provider_")
 		id = strings.ReplaceAll(id, "]", "")
@@ -272,108 +234,27 @@ func sanitizeMermaidID(id string) string {
 	return id
 }
 
-// CleanLabel returns a human-friendly node label suitable for rendering.
-// It removes inline annotations and surrounding quotes, converts `provider[...]` to `provider: ...`, and strips backslashes.
-func CleanLabel(label string) string {
-	label = labelCleaner.ReplaceAllString(label, "")
-	if strings.HasPrefix(label, "provider[") {
-		label = strings.ReplaceAll(label, "[", ": ")
-		label = strings.ReplaceAll(label, "]", "")
-	}
-	label = strings.ReplaceAll(label, "\\", "")
-	return label
+func init() {
+	registerRenderer(ChartTypeFlowchart, flowchartRenderer{})
 }
 
-// isResourceLabel attempts to determine if a Terraform DOT node label represents a resource
-// rather than a provider/module/variable/meta node. This is heuristic-based but works well
-// isResourceLabel reports whether the given Terraform-style label represents a resource node.
-//
-// It returns true for labels that look like Terraform resource or data addresses and false for provider,
-// module or other non-resource labels. The function ignores empty labels and labels beginning with
-// "provider:". Leading module segments ("module.<name>") are stripped before analysis. For data
-// addresses ("data.<type>.<name>") the type segment is inspected; for other addresses the type is taken
-// from the canonical position in the address. Detection is guided by an optional user-supplied regex
-// matcher and optional user prefixes, falls back to a set of built-in provider prefixes, and finally
-// treats any type segment containing an underscore ('_') as a resource.
-func isResourceLabel(label string) bool {
-	if label == "" {
-		return false
-	}
-
-	// Exclude providers explicitly handled elsewhere
-	if strings.HasPrefix(label, "provider:") {
-		return false
-	}
-
-	// Terraform graph labels generally follow addressing like:
-	// - <type>.<name>
-	// - module.<mod>.<type>.<name>
-	// - data.<type>.<name> (optionally with module prefixes)
-	parts := strings.Split(label, ".")
-	if len(parts) < 2 {
-		return false
-	}
-
-	// Strip any number of leading module segments: module.<name>.
-	for len(parts) >= 2 && parts[0] == "module" {
-		parts = parts[2:]
-	}
-
-	if len(parts) < 2 {
-		return false
-	}
-
-	// Determine the resource type segment
-	var typeSeg string
-	if parts[0] == "data" {
-		// data.<type>.<name>
-		if len(parts) < 3 {
-			return false
-		}
-		typeSeg = parts[1]
-	} else {
-		// <type>.<name> (possibly with extra addressing suffixes)
-		typeIdx := len(parts) - 2
-		if typeIdx < 0 {
-			return false
-		}
-		typeSeg = parts[typeIdx]
-	}
-
-	// Optional custom matcher provided by user
-	if customResourceTypeMatcher != nil && customResourceTypeMatcher.MatchString(typeSeg) {
-		return true
-	}
-
-	// Optional custom prefixes provided by user
-	for _, pref := range customResourceTypePrefixes {
-		if strings.HasPrefix(typeSeg, pref) {
-			return true
-		}
-	}
-
-	// Built-in default prefixes for major providers
-	for _, pref := range defaultResourceTypePrefixes {
-		if strings.HasPrefix(typeSeg, pref) {
-			return true
-		}
-	}
+// flowchartRenderer renders graphs with GenerateMermaidFlowchart.
+type flowchartRenderer struct{}
 
-	// Generic heuristic: most Terraform resource types contain an underscore
-	// (e.g., aws_s3_bucket, azurerm_resource_group, google_compute_instance, customprov_widget)
-	if strings.Contains(typeSeg, "_") {
-		return true
-	}
+func (flowchartRenderer) Description() string {
+	return "Mermaid flowchart of every node and dependency (default)"
+}
 
-	return false
+func (flowchartRenderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	return GenerateMermaidFlowchart(ctx, graph, opts.Direction, opts.SubgraphName, opts.ResourcesOnly, opts.Filter, opts.Verbose)
 }
 
-// GenerateMermaidFlowchart generates a Mermaid flowchart diagram from a gographviz graph.
+// GenerateMermaidFlowchart generates a Mermaid flowchart diagram from a graph.
 // It validates the layout direction (must be one of TB, TD, BT, RL, LR) and returns an error for invalid directions.
 // The output may include an optional named subgraph, can be limited to Terraform resource-like nodes when resourcesOnly is true, and is filtered by the provided FilterConfig (a nil filter is treated as empty).
 // When verbose is true the function emits progress messages via the utils logger.
 // It returns the complete Mermaid diagram as a string or an error if validation fails.
-func GenerateMermaidFlowchart(ctx context.Context, graph *gographviz.Graph, direction string, subgraphName string, resourcesOnly bool, filter *FilterConfig, verbose bool) (string, error) {
+func GenerateMermaidFlowchart(ctx context.Context, graph *model.Graph, direction string, subgraphName string, resourcesOnly bool, filter *FilterConfig, verbose bool) (string, error) {
 	if !validDirections[direction] {
 		return "", fmt.Errorf("%w %s: valid options are TB, TD, BT, RL, LR", errInvalidDirection, direction)
 	}
@@ -396,31 +277,90 @@ func GenerateMermaidFlowchart(ctx context.Context, graph *gographviz.Graph, dire
 		verbose:        verbose,
 	}
 
-	state.appendNodes(&sb, graph)
+	state.selectNodes(graph)
+	state.selectEdges(graph)
+	state.writeNodes(&sb)
 
 	if subgraphName != "" {
 		sb.WriteString("    end\n")
 	}
 
-	state.appendEdges(&sb, graph)
+	state.writeEdges(&sb)
+	state.appendCycleStyles(&sb)
+	state.appendActionClasses(&sb, graph)
 
 	sb.WriteString("```\n")
 
 	if verbose {
 		nodeCount := len(state.addedNodes)
-		edgeCount := len(graph.Edges.Edges)
+		edgeCount := len(graph.Edges)
 		utils.LogVerbose("Mermaid diagram generation complete with %d nodes and %d edges", nodeCount, edgeCount)
 	}
 
 	return sb.String(), nil
 }
 
+// AddMermaidTitle adds title to a Mermaid diagram as YAML frontmatter. An empty title leaves the diagram unchanged.
+func AddMermaidTitle(diagram string, title string) string {
+	const fence = "```mermaid\n"
+	if title == "" || !strings.HasPrefix(diagram, fence) {
+		return diagram
+	}
+	return fence + "---\ntitle: " + strconv.Quote(title) + "\n---\n" + strings.TrimPrefix(diagram, fence)
+}
+
+// flowchartState selects the nodes and edges of a graph that a diagram draws, honouring resourcesOnly and the
+// filter. Selection is separate from writing so that other formats draw the same nodes and edges as flowcharts.
 type flowchartState struct {
+	// idPrefix namespaces node IDs when several graphs are rendered into one diagram.
+	idPrefix       string
 	addedNodes     map[string]string
 	addedProviders map[string]bool
 	resourcesOnly  bool
 	filter         *FilterConfig
 	verbose        bool
+	nodes          []selectedNode
+	edges          []selectedEdge
+	// selectingEdges is set once edges are selected; nodes added from then on are edge endpoints.
+	selectingEdges bool
+}
+
+// selectFlowchart selects the nodes and edges the Mermaid flowchart of graph draws with opts, for formats that
+// draw the same diagram in another language. It returns the selection and the filter it applied.
+func selectFlowchart(ctx context.Context, graph *model.Graph, opts RenderOptions) (flowchartState, *FilterConfig, error) {
+	filter := normalizeFilter(opts.Filter)
+	logFilterOptions(filter)
+	state := flowchartState{
+		addedNodes:     make(map[string]string),
+		addedProviders: make(map[string]bool),
+		resourcesOnly:  opts.ResourcesOnly,
+		filter:         filter,
+		verbose:        opts.Verbose,
+	}
+	state.selectNodes(graph)
+	state.selectEdges(graph)
+	return state, filter, ctx.Err()
+}
+
+// selectedNode is a node the diagram draws.
+type selectedNode struct {
+	id    string
+	label string
+	node  *model.Node
+	// edge is the index of the edge whose endpoints added the node, or -1 for nodes added before any edge.
+	edge int
+}
+
+// selectedEdge is an edge the diagram draws between two selected nodes.
+type selectedEdge struct {
+	from, to string
+	kind     model.EdgeKind
+	color    string // Color terraform graph -draw-cycles gives edges in a dependency cycle
+}
+
+// nodeID returns the Mermaid node ID for a graph node name.
+func (s *flowchartState) nodeID(name string) string {
+	return s.idPrefix + CleanID(name)
 }
 
 func normalizeFilter(filter *FilterConfig) *FilterConfig {
@@ -462,37 +402,42 @@ func logFilterOptions(filter *FilterConfig) {
 	}
 }
 
-func (s *flowchartState) appendNodes(sb *strings.Builder, graph *gographviz.Graph) {
+func (s *flowchartState) selectNodes(graph *model.Graph) {
 	if s.verbose {
-		utils.LogVerbose("Processing %d nodes", len(graph.Nodes.Nodes))
+		utils.LogVerbose("Processing %d nodes", len(graph.Nodes))
 	}
 
-	for _, node := range graph.Nodes.Nodes {
-		nodeID := CleanID(node.Name)
-		nodeLabel := CleanLabel(node.Attrs["label"])
-		if !s.shouldAppendNode(nodeID, nodeLabel) {
+	for _, node := range graph.Nodes {
+		nodeID := s.nodeID(node.ID)
+		if !s.shouldAppendNode(nodeID, node) {
 			continue
 		}
-		s.addNode(sb, nodeID, nodeLabel, "Added node: %s")
+		s.addNode(nodeID, node, "Added node: %s")
 	}
 }
 
-func (s *flowchartState) shouldAppendNode(nodeID string, nodeLabel string) bool {
-	if nodeLabel == "" {
+func (s *flowchartState) shouldAppendNode(nodeID string, node *model.Node) bool {
+	if node.Label == "" {
 		return false
 	}
-	if strings.HasPrefix(nodeLabel, "provider:") {
+	if node.Kind == model.KindProvider {
 		if !s.recordProvider(nodeID) {
 			return false
 		}
 	}
-	if s.resourcesOnly && !isResourceLabel(nodeLabel) {
+	if s.resourcesOnly && !s.isResource(node) {
 		if s.verbose {
-			utils.LogVerbose("Skipping non-resource node due to resourcesOnly: %s (%s)", nodeID, nodeLabel)
+			utils.LogVerbose("Skipping non-resource node due to resourcesOnly: %s (%s)", nodeID, node.Label)
 		}
 		return false
 	}
-	return s.filter.ShouldInclude(nodeLabel, s.verbose)
+	return s.filter.Includes(node, s.verbose)
+}
+
+// isResource classifies node with the filter's provider schema, if any.
+func (s *flowchartState) isResource(node *model.Node) bool {
+	resource, _ := s.filter.classifyNode(node)
+	return resource
 }
 
 func (s *flowchartState) recordProvider(nodeID string) bool {
@@ -506,26 +451,27 @@ func (s *flowchartState) recordProvider(nodeID string) bool {
 	return true
 }
 
-func (s *flowchartState) appendEdges(sb *strings.Builder, graph *gographviz.Graph) {
+func (s *flowchartState) selectEdges(graph *model.Graph) {
 	if s.verbose {
-		utils.LogVerbose("Processing %d edges", len(graph.Edges.Edges))
+		utils.LogVerbose("Processing %d edges", len(graph.Edges))
 	}
 
-	for _, edge := range graph.Edges.Edges {
-		s.appendEdge(sb, graph, edge)
+	s.selectingEdges = true
+	for _, edge := range graph.Edges {
+		s.selectEdge(graph, edge)
 	}
 }
 
-func (s *flowchartState) appendEdge(sb *strings.Builder, graph *gographviz.Graph, edge *gographviz.Edge) {
-	fromID := CleanID(edge.Src)
-	toID := CleanID(edge.Dst)
-	fromLabel := CleanLabel(graph.Nodes.Lookup[edge.Src].Attrs["label"])
-	toLabel := CleanLabel(graph.Nodes.Lookup[edge.Dst].Attrs["label"])
-	fromIncluded := s.includesEndpoint(fromID, fromLabel, "source")
-	toIncluded := s.includesEndpoint(toID, toLabel, "destination")
+func (s *flowchartState) selectEdge(graph *model.Graph, edge *model.Edge) {
+	fromID := s.nodeID(edge.From)
+	toID := s.nodeID(edge.To)
+	from := edgeEndpoint(graph, edge.From)
+	to := edgeEndpoint(graph, edge.To)
+	fromIncluded := s.includesEndpoint(fromID, from, "source")
+	toIncluded := s.includesEndpoint(toID, to, "destination")
 
-	s.addEdgeEndpointNode(sb, fromID, fromLabel, fromIncluded, "Added source node from edge: %s")
-	s.addEdgeEndpointNode(sb, toID, toLabel, toIncluded, "Added destination node from edge: %s")
+	s.addEdgeEndpointNode(fromID, from, fromIncluded, "Added source node from edge: %s")
+	s.addEdgeEndpointNode(toID, to, toIncluded, "Added destination node from edge: %s")
 
 	if !fromIncluded || !toIncluded {
 		if s.verbose {
@@ -534,40 +480,139 @@ func (s *flowchartState) appendEdge(sb *strings.Builder, graph *gographviz.Graph
 		return
 	}
 
-	fmt.Fprintf(sb, "    %s --> %s\n", fromID, toID)
+	s.edges = append(s.edges, selectedEdge{from: fromID, to: toID, kind: edge.Kind, color: edge.Color})
 	if s.verbose {
 		utils.LogVerbose("Added edge: %s --> %s", fromID, toID)
 	}
 }
 
-func (s *flowchartState) includesEndpoint(nodeID string, nodeLabel string, endpoint string) bool {
-	if nodeLabel == "" {
+// writeNodes writes the nodes selected before any edge.
+func (s *flowchartState) writeNodes(sb *strings.Builder) {
+	for _, n := range s.nodes {
+		if n.edge < 0 {
+			fmt.Fprintf(sb, "        %s[\"%s\"]\n", n.id, n.label)
+		}
+	}
+}
+
+// writeEdges writes the selected edges, each preceded by the nodes its endpoints added.
+func (s *flowchartState) writeEdges(sb *strings.Builder) {
+	nodes := s.nodes
+	for i := 0; i <= len(s.edges); i++ {
+		for len(nodes) > 0 && nodes[0].edge <= i {
+			if nodes[0].edge >= 0 {
+				fmt.Fprintf(sb, "        %s[\"%s\"]\n", nodes[0].id, nodes[0].label)
+			}
+			nodes = nodes[1:]
+		}
+		if i < len(s.edges) {
+			fmt.Fprintf(sb, "    %s --> %s\n", s.edges[i].from, s.edges[i].to)
+		}
+	}
+}
+
+// appendCycleStyles styles the edges that `terraform graph -draw-cycles` colors as part of a dependency cycle.
+// Mermaid's linkStyle refers to edges by the order they were written in.
+func (s *flowchartState) appendCycleStyles(sb *strings.Builder) {
+	cycles := 0
+	for i, edge := range s.edges {
+		if edge.color != "" {
+			fmt.Fprintf(sb, "    linkStyle %d stroke:%s,stroke-width:2px\n", i, edge.color)
+			cycles++
+		}
+	}
+	if s.verbose && cycles > 0 {
+		utils.LogVerbose("Highlighted %d cycle edges", cycles)
+	}
+}
+
+// edgeEndpoint returns the node with the given ID, or an unlabelled node if the graph does not contain it.
+func edgeEndpoint(graph *model.Graph, id string) *model.Node {
+	if node := graph.Node(id); node != nil {
+		return node
+	}
+	return &model.Node{ID: id, Kind: model.KindMeta}
+}
+
+func (s *flowchartState) includesEndpoint(nodeID string, node *model.Node, endpoint string) bool {
+	if node.Label == "" {
 		return true
 	}
-	if s.resourcesOnly && !isResourceLabel(nodeLabel) {
+	if s.resourcesOnly && !s.isResource(node) {
 		if s.verbose {
 			utils.LogVerbose("Skipping edge %s (non-resource) due to resourcesOnly: %s", endpoint, nodeID)
 		}
 		return false
 	}
-	return s.filter.ShouldInclude(nodeLabel, s.verbose)
+	return s.filter.Includes(node, s.verbose)
 }
 
-func (s *flowchartState) addEdgeEndpointNode(sb *strings.Builder, nodeID string, nodeLabel string, included bool, logFormat string) {
-	if nodeLabel == "" || !included {
+func (s *flowchartState) addEdgeEndpointNode(nodeID string, node *model.Node, included bool, logFormat string) {
+	if node.Label == "" || !included {
 		return
 	}
-	s.addNode(sb, nodeID, nodeLabel, logFormat)
+	s.addNode(nodeID, node, logFormat)
 }
 
-func (s *flowchartState) addNode(sb *strings.Builder, nodeID string, nodeLabel string, logFormat string) {
+func (s *flowchartState) addNode(nodeID string, node *model.Node, logFormat string) {
 	if _, exists := s.addedNodes[nodeID]; exists {
 		return
 	}
 
-	fmt.Fprintf(sb, "        %s[\"%s\"]\n", nodeID, nodeLabel)
-	s.addedNodes[nodeID] = nodeLabel
-	if s.verbose && !strings.HasPrefix(nodeLabel, "provider:") {
+	edge := -1
+	if s.selectingEdges {
+		edge = len(s.edges)
+	}
+	s.nodes = append(s.nodes, selectedNode{id: nodeID, label: node.Label, node: node, edge: edge})
+	s.addedNodes[nodeID] = node.Label
+	if s.verbose && !strings.HasPrefix(node.Label, "provider:") {
 		utils.LogVerbose(logFormat, nodeID)
 	}
 }
+
+// actionStyle returns the name and value of each Mermaid style property of the class actionClassDefs defines for
+// action, in order, or nil when the action has no class.
+func actionStyle(action string) [][2]string {
+	for _, def := range actionClassDefs {
+		if def.action != action {
+			continue
+		}
+		var properties [][2]string
+		for _, property := range strings.Split(def.style, ",") {
+			name, value, _ := strings.Cut(property, ":")
+			properties = append(properties, [2]string{name, value})
+		}
+		return properties
+	}
+	return nil
+}
+
+// appendActionClasses styles nodes that carry a plan action (see BuildPlanGraph) with one Mermaid class per action.
+// Graphs without plan actions are left untouched.
+func (s *flowchartState) appendActionClasses(sb *strings.Builder, graph *model.Graph) {
+	byAction := make(map[string][]string)
+	for _, node := range graph.Nodes {
+		if node.Action == "" {
+			continue
+		}
+		nodeID := s.nodeID(node.ID)
+		if _, added := s.addedNodes[nodeID]; added {
+			byAction[node.Action] = append(byAction[node.Action], nodeID)
+		}
+	}
+
+	for _, def := range actionClassDefs {
+		nodeIDs := byAction[def.action]
+		if len(nodeIDs) == 0 {
+			continue
+		}
+		className := actionClassName(def.action)
+		fmt.Fprintf(sb, "    classDef %s %s\n", className, def.style)
+		fmt.Fprintf(sb, "    class %s %s\n", strings.Join(nodeIDs, ","), className)
+	}
+}
+
+// actionClassName returns the Mermaid class name for a plan action (e.g. "no-op" becomes "noop").
+func actionClassName(action string) string {
+	return strings.ReplaceAll(action, "-", "")
+}
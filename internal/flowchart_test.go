@@ -4,7 +4,13 @@
 package internal
 
 import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
 )
 
 func TestCleanID(t *testing.T) {
@@ -58,6 +64,31 @@ func TestCleanID(t *testing.T) {
 			input:    "module_eks_public_web_module_self_managed_node_group_var_platform        (validation)    mod",
 			expected: "module_eks_public_web_module_self_managed_node_group_var_platform_validation_mod",
 		},
+		{
+			name:     "terraform graph annotations",
+			input:    `"[root] module.app[\"eu\"].aws_instance.web (expand)"`,
+			expected: "module_app_eu_aws_instance_web",
+		},
+		{
+			name:     "dotted key",
+			input:    `"data.aws_ami.x[\"k.v\"]"`,
+			expected: "data_aws_ami_x_k_v_6d995d8e",
+		},
+		{
+			name:     "key containing an annotation",
+			input:    `"aws_instance.web[\"a (close)\"]"`,
+			expected: "aws_instance_web_a_close_fe6f2583",
+		},
+		{
+			name:     "keys that sanitize alike",
+			input:    `"aws_instance.web[\"a.b\"]"`,
+			expected: "aws_instance_web_a_b_108bf50c",
+		},
+		{
+			name:     "key without invalid characters",
+			input:    `"aws_instance.web[\"a_b\"]"`,
+			expected: "aws_instance_web_a_b",
+		},
 	}
 
 	for _, tt := range tests {
@@ -113,7 +144,7 @@ func TestSanitizeMermaidID(t *testing.T) {
 	}
 }
 
-func TestParseLabelComponents(t *testing.T) {
+func TestNodeComponents(t *testing.T) {
 	tests := []struct {
 		name             string
 		label            string
@@ -191,6 +222,20 @@ func TestParseLabelComponents(t *testing.T) {
 			wantResourceType: "random",
 			wantProvider:     "",
 		},
+		{
+			name:             "keyed module and resource instance",
+			label:            `module.app["eu"].aws_instance.web[0]`,
+			wantModulePath:   "app",
+			wantResourceType: "aws_instance",
+			wantProvider:     "aws",
+		},
+		{
+			name:             "data source with dotted for_each key",
+			label:            `module.net[0].data.aws_ami.x["k.v"]`,
+			wantModulePath:   "net",
+			wantResourceType: "aws_ami",
+			wantProvider:     "aws",
+		},
 		{
 			name:             "module only (incomplete)",
 			label:            "module.foo",
@@ -202,15 +247,15 @@ func TestParseLabelComponents(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotModule, gotType, gotProvider := parseLabelComponents(tt.label)
+			gotModule, gotType, gotProvider := nodeComponents(model.NewNode(tt.label))
 			if gotModule != tt.wantModulePath {
-				t.Errorf("parseLabelComponents(%q) modulePath = %q, want %q", tt.label, gotModule, tt.wantModulePath)
+				t.Errorf("nodeComponents(%q) modulePath = %q, want %q", tt.label, gotModule, tt.wantModulePath)
 			}
 			if gotType != tt.wantResourceType {
-				t.Errorf("parseLabelComponents(%q) resourceType = %q, want %q", tt.label, gotType, tt.wantResourceType)
+				t.Errorf("nodeComponents(%q) resourceType = %q, want %q", tt.label, gotType, tt.wantResourceType)
 			}
 			if gotProvider != tt.wantProvider {
-				t.Errorf("parseLabelComponents(%q) provider = %q, want %q", tt.label, gotProvider, tt.wantProvider)
+				t.Errorf("nodeComponents(%q) provider = %q, want %q", tt.label, gotProvider, tt.wantProvider)
 			}
 		})
 	}
@@ -379,7 +424,7 @@ func TestFilterConfig_IsEmpty(t *testing.T) {
 	}
 }
 
-func TestFilterConfig_ShouldInclude(t *testing.T) {
+func TestFilterConfig_Includes(t *testing.T) {
 	tests := []struct {
 		name   string
 		filter FilterConfig
@@ -522,10 +567,88 @@ func TestFilterConfig_ShouldInclude(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := tt.filter.ShouldInclude(tt.label, false)
+			got := tt.filter.Includes(model.NewNode(tt.label), false)
 			if got != tt.want {
-				t.Errorf("FilterConfig.ShouldInclude(%q) = %v, want %v", tt.label, got, tt.want)
+				t.Errorf("FilterConfig.Includes(%q) = %v, want %v", tt.label, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddMermaidTitle(t *testing.T) {
+	diagram := "```mermaid\nflowchart TD\n```\n"
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{name: "no title", title: "", want: diagram},
+		{name: "title", title: "Graph type: plan-destroy", want: "```mermaid\n---\ntitle: \"Graph type: plan-destroy\"\n---\nflowchart TD\n```\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddMermaidTitle(diagram, tt.title); got != tt.want {
+				t.Errorf("AddMermaidTitle() = %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestGenerateMermaidFlowchart_Cycles(t *testing.T) {
+	const cycleGraph = `digraph G {
+  "aws_a.one" [label="aws_a.one"];
+  "aws_b.two" [label="aws_b.two"];
+  "aws_c.three" [label="aws_c.three"];
+  "aws_c.three" -> "aws_a.one";
+  "aws_a.one" -> "aws_b.two" [color = "red", penwidth = "2.0"];
+  "aws_b.two" -> "aws_a.one" [color = "red", penwidth = "2.0"];
+}
+`
+	graph, err := parseGraph(cycleGraph, false)
+	if err != nil {
+		t.Fatalf("parseGraph() error = %v", err)
+	}
+
+	diagram, err := GenerateMermaidFlowchart(context.Background(), graph, "TD", "", false, nil, false)
+	if err != nil {
+		t.Fatalf("GenerateMermaidFlowchart() error = %v", err)
+	}
+
+	links := 0
+	for _, line := range strings.Split(diagram, "\n") {
+		if !strings.Contains(line, "-->") {
+			continue
+		}
+		style := "    linkStyle " + strconv.Itoa(links) + " stroke:red,stroke-width:2px\n"
+		cycle := !strings.HasPrefix(strings.TrimSpace(line), "aws_c_three")
+		if got := strings.Contains(diagram, style); got != cycle {
+			t.Errorf("edge %q styled = %v, want %v", line, got, cycle)
+		}
+		links++
+	}
+	if links != 3 {
+		t.Errorf("diagram has %d edges, want 3:\n%s", links, diagram)
+	}
+}
+
+var mermaidIDPattern = regexp.MustCompile(`^[A-Za-z_][^()\[\]{}<>\s\-:;,!@#$%^&*+=|\\?'"` + "`" + `~]*$`)
+
+func FuzzCleanID(f *testing.F) {
+	for _, seed := range []string{
+		"aws_instance.web",
+		`module.app["eu"].aws_instance.web[0]`,
+		`"[root] module.vpc (expand)"`,
+		`provider["registry.terraform.io/hashicorp/aws"]`,
+		"aws_instance.web[",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		if got := CleanID(input); !mermaidIDPattern.MatchString(got) {
+			t.Fatalf("CleanID(%q) = %q is not a safe Mermaid ID", input, got)
+		}
+	})
+}
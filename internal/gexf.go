@@ -0,0 +1,138 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// FormatGEXF is the output format of GEXF graphs.
+const FormatGEXF = "gexf"
+
+func init() {
+	registerFormat(FormatGEXF, ".gexf", gexfRenderer{})
+}
+
+// gexfRenderer renders graphs as GEXF 1.2 for Gephi and networkx.
+type gexfRenderer struct{}
+
+func (gexfRenderer) Description() string {
+	return "GEXF graph with typed node attributes for Gephi and networkx"
+}
+
+type gexfDocument struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Meta    gexfMeta  `xml:"meta"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfMeta struct {
+	LastModified string `xml:"lastmodifieddate,attr"`
+	Creator      string `xml:"creator"`
+	Description  string `xml:"description,omitempty"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+// gexfAttributes declares the typed attributes of nodes or edges.
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Render exports a directed GEXF graph. Node kinds, modules, types, providers and plan actions are declared as
+// string attributes next to the native labels, and unknown values have no attvalue.
+func (gexfRenderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	state, filter, err := selectFlowchart(ctx, graph, opts)
+	if err != nil {
+		return "", err
+	}
+
+	doc := gexfDocument{
+		XMLNS:   "http://www.gexf.net/1.2draft",
+		Version: "1.2",
+		Meta:    gexfMeta{LastModified: opts.generationTime().UTC().Format(time.DateOnly), Creator: "Terramaid"},
+		Graph:   gexfGraph{DefaultEdgeType: "directed", Mode: "static"},
+	}
+	if graph.TerraformVersion != "" {
+		doc.Meta.Description = "Terraform " + graph.TerraformVersion
+	}
+	nodeAttributes := gexfAttributes{Class: "node"}
+	for _, attr := range (jsonNode{}).attributes() {
+		nodeAttributes.Attributes = append(nodeAttributes.Attributes, gexfAttribute{ID: attr[0], Title: attr[0], Type: "string"})
+	}
+	doc.Graph.Attributes = []gexfAttributes{
+		nodeAttributes,
+		{Class: "edge", Attributes: []gexfAttribute{{ID: "kind", Title: "kind", Type: "string"}}},
+	}
+
+	for _, n := range state.nodes {
+		exported := exportNode(n, filter)
+		node := gexfNode{ID: exported.ID, Label: exported.Label}
+		for _, attr := range exported.attributes() {
+			if attr[1] != "" {
+				node.AttValues = append(node.AttValues, gexfAttValue{For: attr[0], Value: attr[1]})
+			}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for i, e := range state.edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:        strconv.Itoa(i),
+			Source:    e.from,
+			Target:    e.to,
+			AttValues: []gexfAttValue{{For: "kind", Value: string(e.kind)}},
+		})
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errEncodeXML, err)
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("GEXF generation complete with %d nodes and %d edges", len(state.nodes), len(state.edges))
+	}
+
+	return xml.Header + string(b) + "\n", nil
+}
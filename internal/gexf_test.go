@@ -0,0 +1,58 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/xml"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGEXFRenderer(t *testing.T) {
+	graph, err := ParsePlanJSONFile(context.Background(), "testdata/plan.json", false)
+	if err != nil {
+		t.Fatalf("ParsePlanJSONFile() error = %v", err)
+	}
+
+	diagram, err := gexfRenderer{}.Render(context.Background(), graph, RenderOptions{
+		ResourcesOnly: true,
+		GeneratedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	var doc gexfDocument
+	if err := xml.Unmarshal([]byte(diagram), &doc); err != nil {
+		t.Fatalf("Render() wrote invalid XML: %v\n%s", err, diagram)
+	}
+
+	if doc.Version != "1.2" || doc.Meta.LastModified != "2026-01-02" || doc.Meta.Description != "Terraform 1.9.5" {
+		t.Errorf("Render() document = %s %+v", doc.Version, doc.Meta)
+	}
+	if len(doc.Graph.Attributes) != 2 || doc.Graph.Attributes[0].Class != "node" || doc.Graph.Attributes[1].Class != "edge" {
+		t.Fatalf("Render() attributes = %+v", doc.Graph.Attributes)
+	}
+	if want := (gexfAttribute{ID: "action", Title: "action", Type: "string"}); !slices.Contains(doc.Graph.Attributes[0].Attributes, want) {
+		t.Errorf("Render() node attributes = %+v, want %+v among them", doc.Graph.Attributes[0].Attributes, want)
+	}
+
+	want := gexfNode{
+		ID:    "module_app_eu_aws_instance_web",
+		Label: "module.app[eu].aws_instance.web",
+		AttValues: []gexfAttValue{
+			{For: "kind", Value: "resource"}, {For: "module", Value: "app"}, {For: "type", Value: "aws_instance"},
+			{For: "provider", Value: "registry.terraform.io/hashicorp/aws"}, {For: "action", Value: "replace"},
+		},
+	}
+	if !slices.ContainsFunc(doc.Graph.Nodes, func(n gexfNode) bool {
+		return n.ID == want.ID && n.Label == want.Label && slices.Equal(n.AttValues, want.AttValues)
+	}) {
+		t.Errorf("Render() nodes = %+v, want %+v among them", doc.Graph.Nodes, want)
+	}
+	if len(doc.Graph.Edges) == 0 || !slices.Equal(doc.Graph.Edges[0].AttValues, []gexfAttValue{{For: "kind", Value: "dependency"}}) {
+		t.Errorf("Render() edges = %+v", doc.Graph.Edges)
+	}
+}
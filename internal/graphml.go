@@ -0,0 +1,129 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// FormatGraphML is the output format of GraphML graphs.
+const FormatGraphML = "graphml"
+
+func init() {
+	registerFormat(FormatGraphML, ".graphml", graphmlRenderer{})
+}
+
+// graphmlRenderer renders graphs as GraphML for graph analysis tools such as yEd and networkx.
+type graphmlRenderer struct{}
+
+func (graphmlRenderer) Description() string {
+	return "GraphML graph with typed node attributes for yEd, networkx and other graph tools"
+}
+
+type graphmlDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+// graphmlKey declares a typed attribute of graphs, nodes or edges.
+type graphmlKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphmlGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphmlData `xml:"data"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Render exports a directed GraphML graph. Node labels, kinds, modules, types, providers and plan actions and
+// edge kinds are declared as string keys, and nodes leave out the keys they have no value for.
+func (graphmlRenderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	state, filter, err := selectFlowchart(ctx, graph, opts)
+	if err != nil {
+		return "", err
+	}
+
+	doc := graphmlDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphmlKey{
+			{ID: "terraform_version", For: "graph", Name: "terraform_version", Type: "string"},
+			{ID: "generated_at", For: "graph", Name: "generated_at", Type: "string"},
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+		},
+		Graph: graphmlGraph{
+			ID:          "terramaid",
+			EdgeDefault: "directed",
+			Data:        []graphmlData{{Key: "generated_at", Value: opts.generationTime().UTC().Format(time.RFC3339)}},
+		},
+	}
+	if graph.TerraformVersion != "" {
+		doc.Graph.Data = append([]graphmlData{{Key: "terraform_version", Value: graph.TerraformVersion}}, doc.Graph.Data...)
+	}
+	for _, attr := range (jsonNode{}).attributes() {
+		doc.Keys = append(doc.Keys, graphmlKey{ID: attr[0], For: "node", Name: attr[0], Type: "string"})
+	}
+	doc.Keys = append(doc.Keys, graphmlKey{ID: "edge_kind", For: "edge", Name: "kind", Type: "string"})
+
+	for _, n := range state.nodes {
+		exported := exportNode(n, filter)
+		node := graphmlNode{ID: exported.ID, Data: []graphmlData{{Key: "label", Value: exported.Label}}}
+		for _, attr := range exported.attributes() {
+			if attr[1] != "" {
+				node.Data = append(node.Data, graphmlData{Key: attr[0], Value: attr[1]})
+			}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for i, e := range state.edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			ID:     "e" + strconv.Itoa(i),
+			Source: e.from,
+			Target: e.to,
+			Data:   []graphmlData{{Key: "edge_kind", Value: string(e.kind)}},
+		})
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errEncodeXML, err)
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("GraphML generation complete with %d nodes and %d edges", len(state.nodes), len(state.edges))
+	}
+
+	return xml.Header + string(b) + "\n", nil
+}
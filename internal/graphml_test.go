@@ -0,0 +1,68 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/xml"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGraphMLRenderer(t *testing.T) {
+	graph, err := ParseStatic(context.Background(), "testdata/static", false)
+	if err != nil {
+		t.Fatalf("ParseStatic() error = %v", err)
+	}
+
+	diagram, err := graphmlRenderer{}.Render(context.Background(), graph, RenderOptions{
+		GeneratedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	var doc graphmlDocument
+	if err := xml.Unmarshal([]byte(diagram), &doc); err != nil {
+		t.Fatalf("Render() wrote invalid XML: %v\n%s", err, diagram)
+	}
+
+	for _, want := range []graphmlKey{
+		{ID: "label", For: "node", Name: "label", Type: "string"},
+		{ID: "provider", For: "node", Name: "provider", Type: "string"},
+		{ID: "edge_kind", For: "edge", Name: "kind", Type: "string"},
+	} {
+		if !slices.Contains(doc.Keys, want) {
+			t.Errorf("Render() keys = %+v, want %+v among them", doc.Keys, want)
+		}
+	}
+	if doc.Graph.EdgeDefault != "directed" || !slices.Contains(doc.Graph.Data, graphmlData{Key: "generated_at", Value: "2026-01-02T03:04:05Z"}) {
+		t.Errorf("Render() graph = %s %+v", doc.Graph.EdgeDefault, doc.Graph.Data)
+	}
+
+	nodes := make(map[string][]graphmlData)
+	for _, n := range doc.Graph.Nodes {
+		nodes[n.ID] = n.Data
+	}
+	tests := map[string][]graphmlData{
+		"module_app_aws_instance_web": {
+			{Key: "label", Value: "module.app.aws_instance.web"}, {Key: "kind", Value: "resource"},
+			{Key: "module", Value: "app"}, {Key: "type", Value: "aws_instance"}, {Key: "provider", Value: "registry.terraform.io/hashicorp/aws"},
+		},
+		"var_cidr_block": {{Key: "label", Value: "var.cidr_block"}, {Key: "kind", Value: "variable"}},
+		"module_app":     {{Key: "label", Value: "module.app"}, {Key: "kind", Value: "module"}},
+	}
+	for id, want := range tests {
+		if got := nodes[id]; !slices.Equal(got, want) {
+			t.Errorf("node %s data = %+v, want %+v", id, got, want)
+		}
+	}
+
+	want := graphmlEdge{Source: "aws_subnet_private", Target: "aws_vpc_main", Data: []graphmlData{{Key: "edge_kind", Value: "dependency"}}}
+	if !slices.ContainsFunc(doc.Graph.Edges, func(e graphmlEdge) bool {
+		return e.Source == want.Source && e.Target == want.Target && slices.Equal(e.Data, want.Data)
+	}) {
+		t.Errorf("Render() edges = %+v, want %+v among them", doc.Graph.Edges, want)
+	}
+}
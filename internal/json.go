@@ -0,0 +1,155 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// FormatJSON is the output format of JSON graphs, described by docs/terramaid-graph.schema.json.
+const FormatJSON = "json"
+
+func init() {
+	registerFormat(FormatJSON, ".json", jsonRenderer{})
+}
+
+// jsonRenderer renders graphs as JSON for scripts and dashboards.
+type jsonRenderer struct{}
+
+func (jsonRenderer) Description() string {
+	return "JSON nodes, edges and metadata described by a published JSON Schema"
+}
+
+// jsonGraph is the document jsonRenderer writes. Changes must be reflected in docs/terramaid-graph.schema.json.
+type jsonGraph struct {
+	Nodes    []jsonNode   `json:"nodes"`
+	Edges    []jsonEdge   `json:"edges"`
+	Metadata jsonMetadata `json:"metadata"`
+}
+
+type jsonNode struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Kind     string `json:"kind"`
+	Module   string `json:"module,omitempty"`   // Dot-joined module call names, e.g. network.subnets
+	Type     string `json:"type,omitempty"`     // Resource or data source type
+	Provider string `json:"provider,omitempty"` // Provider source address, e.g. registry.terraform.io/hashicorp/aws
+	Action   string `json:"action,omitempty"`   // Planned change action
+}
+
+type jsonEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+type jsonMetadata struct {
+	TerraformVersion string      `json:"terraform_version,omitempty"`
+	GeneratedAt      string      `json:"generated_at"`
+	Filters          jsonFilters `json:"filters"`
+}
+
+type jsonFilters struct {
+	IncludeTypes     []string `json:"include_types"`
+	ExcludeTypes     []string `json:"exclude_types"`
+	IncludeProviders []string `json:"include_providers"`
+	ExcludeModules   []string `json:"exclude_modules"`
+	ResourcesOnly    bool     `json:"resources_only"`
+}
+
+// Render encodes the selected nodes and edges together with the filters that selected them. IDs are the cleaned
+// IDs the other formats use.
+func (jsonRenderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	state, filter, err := selectFlowchart(ctx, graph, opts)
+	if err != nil {
+		return "", err
+	}
+
+	doc := jsonGraph{
+		Nodes: make([]jsonNode, 0, len(state.nodes)),
+		Edges: make([]jsonEdge, 0, len(state.edges)),
+		Metadata: jsonMetadata{
+			TerraformVersion: graph.TerraformVersion,
+			GeneratedAt:      opts.generationTime().UTC().Format(time.RFC3339),
+			Filters: jsonFilters{
+				IncludeTypes:     nonNil(filter.IncludeTypes),
+				ExcludeTypes:     nonNil(filter.ExcludeTypes),
+				IncludeProviders: nonNil(filter.IncludeProviders),
+				ExcludeModules:   nonNil(filter.ExcludeModules),
+				ResourcesOnly:    opts.ResourcesOnly,
+			},
+		},
+	}
+	for _, n := range state.nodes {
+		doc.Nodes = append(doc.Nodes, exportNode(n, filter))
+	}
+	for _, e := range state.edges {
+		doc.Edges = append(doc.Edges, jsonEdge{From: e.from, To: e.to, Kind: string(e.kind)})
+	}
+
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errEncodeJSON, err)
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("JSON graph generation complete with %d nodes and %d edges", len(doc.Nodes), len(doc.Edges))
+	}
+
+	return string(b) + "\n", nil
+}
+
+// exportNode returns the properties of a selected node that the JSON, GraphML and GEXF formats export.
+func exportNode(n selectedNode, filter *FilterConfig) jsonNode {
+	exported := jsonNode{
+		ID:       n.id,
+		Label:    model.CleanLabel(n.label),
+		Kind:     string(n.node.Kind),
+		Module:   containerPath(n.node, GroupByModule, filter),
+		Provider: providerSource(n.node, filter),
+		Action:   n.node.Action,
+	}
+	if isResourceNode(n.node) {
+		exported.Type = n.node.Address.Type
+	}
+	return exported
+}
+
+// providerSource returns the source address of the provider managing n: the provider resolved by the filter, the
+// provider recorded on the node, or, for resources, the provider Terraform implies from the prefix of their type.
+// It is empty for other nodes of unknown provider.
+func providerSource(n *model.Node, filter *FilterConfig) string {
+	if ref, ok := filter.Providers[n.Label]; ok && ref.Source != "" {
+		return ref.Source
+	}
+	if n.Provider != "" {
+		return n.Provider
+	}
+	if isResourceNode(n) && n.Address.Provider() != "" {
+		return normalizeProviderSource(n.Address.Provider())
+	}
+	return ""
+}
+
+// attributes returns the name and value of the node attributes exported as typed keys, in order. Unknown
+// values are empty.
+func (n jsonNode) attributes() [][2]string {
+	return [][2]string{
+		{"kind", n.Kind}, {"module", n.Module}, {"type", n.Type}, {"provider", n.Provider}, {"action", n.Action},
+	}
+}
+
+// nonNil returns s, or an empty slice when s is nil, so it is encoded as an empty JSON array.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
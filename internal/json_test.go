@@ -0,0 +1,154 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+)
+
+func TestJSONRenderer(t *testing.T) {
+	graph, err := ParsePlanJSONFile(context.Background(), "testdata/plan.json", false)
+	if err != nil {
+		t.Fatalf("ParsePlanJSONFile() error = %v", err)
+	}
+
+	diagram, err := jsonRenderer{}.Render(context.Background(), graph, RenderOptions{
+		ResourcesOnly: true,
+		Filter:        &FilterConfig{ExcludeTypes: []string{"aws_route53_record"}},
+		GeneratedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	var got jsonGraph
+	if err := json.Unmarshal([]byte(diagram), &got); err != nil {
+		t.Fatalf("Render() wrote invalid JSON: %v\n%s", err, diagram)
+	}
+
+	wantNode := jsonNode{
+		ID:       "module_app_eu_aws_instance_web",
+		Label:    "module.app[eu].aws_instance.web",
+		Kind:     "resource",
+		Module:   "app",
+		Type:     "aws_instance",
+		Provider: "registry.terraform.io/hashicorp/aws",
+		Action:   "replace",
+	}
+	if !slices.Contains(got.Nodes, wantNode) {
+		t.Errorf("Render() nodes = %+v, want %+v among them", got.Nodes, wantNode)
+	}
+	for _, n := range got.Nodes {
+		if n.Type == "aws_route53_record" {
+			t.Errorf("Render() includes excluded node %+v", n)
+		}
+	}
+	wantEdge := jsonEdge{From: "aws_subnet_private_0", To: "aws_vpc_main", Kind: "dependency"}
+	if !slices.Contains(got.Edges, wantEdge) {
+		t.Errorf("Render() edges = %+v, want %+v among them", got.Edges, wantEdge)
+	}
+
+	meta := got.Metadata
+	if meta.TerraformVersion != "1.9.5" || meta.GeneratedAt != "2026-01-02T02:04:05Z" {
+		t.Errorf("Render() metadata = %+v", meta)
+	}
+	if !meta.Filters.ResourcesOnly || !slices.Equal(meta.Filters.ExcludeTypes, []string{"aws_route53_record"}) || meta.Filters.IncludeTypes == nil {
+		t.Errorf("Render() filters = %+v", meta.Filters)
+	}
+}
+
+func TestProviderSource(t *testing.T) {
+	resolved := &FilterConfig{Providers: map[string]ProviderRef{
+		"mycloud_thing.x": {Source: "registry.terraform.io/acme/cloud", LocalName: "mycloud"},
+	}}
+	tests := []struct {
+		name   string
+		label  string
+		filter *FilterConfig
+		want   string
+	}{
+		{name: "resolved", label: "mycloud_thing.x", filter: resolved, want: "registry.terraform.io/acme/cloud"},
+		{name: "implied", label: "aws_instance.web", filter: resolved, want: "registry.terraform.io/hashicorp/aws"},
+		{name: "data source", label: "data.google_project.this", filter: &FilterConfig{}, want: "registry.terraform.io/hashicorp/google"},
+		{name: "provider node", label: `provider["registry.terraform.io/acme/cloud"].eu`, filter: &FilterConfig{}, want: "registry.terraform.io/acme/cloud"},
+		{name: "variable", label: "var.region", filter: &FilterConfig{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := providerSource(model.NewNode(tt.label), tt.filter); got != tt.want {
+				t.Errorf("providerSource(%s) = %q, want %q", tt.label, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJSONSchema checks that the JSON output only uses properties and values the published schema allows.
+func TestJSONSchema(t *testing.T) {
+	data, err := os.ReadFile("../docs/terramaid-graph.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Defs map[string]struct {
+			Required   []string `json:"required"`
+			Properties map[string]struct {
+				Enum []string `json:"enum"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+
+	graph, err := ParsePlanJSONFile(context.Background(), "testdata/plan.json", false)
+	if err != nil {
+		t.Fatalf("ParsePlanJSONFile() error = %v", err)
+	}
+	diagram, err := jsonRenderer{}.Render(context.Background(), graph, RenderOptions{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	var doc struct {
+		Nodes    []map[string]any `json:"nodes"`
+		Edges    []map[string]any `json:"edges"`
+		Metadata map[string]any   `json:"metadata"`
+	}
+	if err := json.Unmarshal([]byte(diagram), &doc); err != nil {
+		t.Fatalf("Render() wrote invalid JSON: %v", err)
+	}
+
+	check := func(def string, object map[string]any) {
+		d := schema.Defs[def]
+		for _, name := range d.Required {
+			if _, ok := object[name]; !ok {
+				t.Errorf("%s %v is missing required %q", def, object, name)
+			}
+		}
+		for name, value := range object {
+			property, ok := d.Properties[name]
+			if !ok {
+				t.Errorf("%s property %q is not in the schema", def, name)
+				continue
+			}
+			if s, isString := value.(string); isString && len(property.Enum) > 0 && !slices.Contains(property.Enum, s) {
+				t.Errorf("%s %s %q is not one of %v", def, name, s, property.Enum)
+			}
+		}
+	}
+	for _, n := range doc.Nodes {
+		check("node", n)
+	}
+	for _, e := range doc.Edges {
+		check("edge", e)
+	}
+	check("metadata", doc.Metadata)
+}
@@ -0,0 +1,287 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+// Package model is Terramaid's typed representation of a Terraform dependency graph. Builders turn terraform graph
+// DOT output, plan JSON and state into a Graph so that filters and renderers never parse raw labels themselves.
+package model
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+// Resource modes of a parsed address.
+const (
+	ModeManaged = "managed"
+	ModeData    = "data"
+)
+
+// idKey matches instance keys that can appear in a node ID as is: letters and digits joined by single underscores.
+var idKey = regexp.MustCompile(`^[A-Za-z0-9]+(?:_[A-Za-z0-9]+)*$`)
+
+// nodeAnnotations matches the decorations terraform graph adds around an address, e.g. `[root] ` and ` (expand)`.
+var nodeAnnotations = regexp.MustCompile(`^\[root\]\s*|\s*\((?:expand|close)\)$`)
+
+// ModuleInstance is one module call in an address together with its instance key.
+type ModuleInstance struct {
+	Name string
+	Key  string // Instance key as written between the brackets, e.g. `0` or `"eu"`; empty when not keyed
+}
+
+// Address is a parsed Terraform resource or module address such as
+// `module.app["eu"].data.aws_ami.ubuntu[0]`.
+type Address struct {
+	Module []ModuleInstance // Module path from the root module, outermost first
+	Mode   string           // ModeManaged or ModeData; empty when the address names a module
+	Type   string
+	Name   string
+	Key    string // Resource instance key as written between the brackets; empty when not keyed
+}
+
+// addressStep is one dot-separated segment of an address with its optional instance key.
+type addressStep struct {
+	name  string
+	key   string
+	keyed bool
+}
+
+// ParseAddress parses a Terraform resource or module address. Instance keys may be numbers, quoted strings
+// (which may contain dots, brackets and escaped quotes) or, as in labels whose quotes have been removed, bare
+// text up to the closing bracket.
+func ParseAddress(s string) (Address, error) {
+	steps, err := splitAddress(s)
+	if err != nil {
+		return Address{}, err
+	}
+
+	var addr Address
+	for len(steps) >= 2 && steps[0].name == "module" && !steps[0].keyed {
+		addr.Module = append(addr.Module, ModuleInstance{Name: steps[1].name, Key: steps[1].key})
+		steps = steps[2:]
+	}
+
+	if len(steps) == 0 {
+		if len(addr.Module) == 0 {
+			return Address{}, fmt.Errorf("%w %q: empty address", errInvalidAddress, s)
+		}
+		return addr, nil
+	}
+
+	addr.Mode = ModeManaged
+	if steps[0].name == "data" && !steps[0].keyed {
+		addr.Mode = ModeData
+		steps = steps[1:]
+	}
+
+	if len(steps) != 2 {
+		return Address{}, fmt.Errorf("%w %q: expected a resource type and name", errInvalidAddress, s)
+	}
+	if steps[0].keyed {
+		return Address{}, fmt.Errorf("%w %q: resource type %s cannot have an instance key", errInvalidAddress, s, steps[0].name)
+	}
+
+	addr.Type = steps[0].name
+	addr.Name = steps[1].name
+	addr.Key = steps[1].key
+	return addr, nil
+}
+
+// splitAddress splits s into its dot-separated steps.
+func splitAddress(s string) ([]addressStep, error) {
+	var steps []addressStep
+	for i := 0; ; {
+		start := i
+		for i < len(s) && isIdentifierByte(s[i], i == start) {
+			i++
+		}
+		if i == start {
+			return nil, fmt.Errorf("%w %q: expected a name at offset %d", errInvalidAddress, s, start)
+		}
+		step := addressStep{name: s[start:i]}
+
+		if i < len(s) && s[i] == '[' {
+			end, err := scanInstanceKey(s, i)
+			if err != nil {
+				return nil, err
+			}
+			step.key = s[i+1 : end]
+			step.keyed = true
+			i = end + 1
+		}
+		steps = append(steps, step)
+
+		if i == len(s) {
+			return steps, nil
+		}
+		if s[i] != '.' {
+			return nil, fmt.Errorf("%w %q: unexpected %q at offset %d", errInvalidAddress, s, s[i], i)
+		}
+		i++
+	}
+}
+
+// scanInstanceKey returns the offset of the `]` closing the instance key that opens at s[open].
+func scanInstanceKey(s string, open int) (int, error) {
+	i := open + 1
+	if i < len(s) && s[i] == '"' {
+		for i++; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(s) {
+			return 0, fmt.Errorf("%w %q: unterminated string key at offset %d", errInvalidAddress, s, open)
+		}
+		i++
+		if i >= len(s) || s[i] != ']' {
+			return 0, fmt.Errorf("%w %q: expected ] after string key at offset %d", errInvalidAddress, s, i)
+		}
+		return i, nil
+	}
+
+	end := strings.IndexByte(s[i:], ']')
+	if end <= 0 || strings.ContainsAny(s[i:i+end], `["`) {
+		return 0, fmt.Errorf("%w %q: invalid instance key at offset %d", errInvalidAddress, s, open)
+	}
+	return i + end, nil
+}
+
+// isIdentifierByte reports whether c may appear in a Terraform identifier; first reports whether c is the
+// identifier's first byte.
+func isIdentifierByte(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case c >= '0' && c <= '9', c == '-':
+		return !first
+	default:
+		return false
+	}
+}
+
+// String returns the address in Terraform syntax with instance keys as written.
+func (a Address) String() string {
+	return a.format(func(key string) string { return key })
+}
+
+// ModulePath returns the dot-joined names of the address's module calls without instance keys, e.g. "network.subnets".
+func (a Address) ModulePath() string {
+	names := make([]string, len(a.Module))
+	for i, m := range a.Module {
+		names[i] = m.Name
+	}
+	return strings.Join(names, ".")
+}
+
+// Provider returns the provider name implied by the resource type, i.e. the prefix before its first underscore.
+func (a Address) Provider() string {
+	if idx := strings.Index(a.Type, "_"); idx > 0 {
+		return a.Type[:idx]
+	}
+	return ""
+}
+
+// Label returns the address as displayed in diagrams: quotes and escapes are removed from instance keys.
+func (a Address) Label() string {
+	return a.format(unquoteKey)
+}
+
+// ID returns the underscore-joined segments of the address, with instance keys unquoted, for use as a node ID.
+// Keys that sanitizing would change are suffixed with a hash (see keyID), so that e.g. ["a.b"] and ["a_b"] never
+// share an ID.
+func (a Address) ID() string {
+	var parts []string
+	for _, m := range a.Module {
+		parts = append(parts, "module", m.Name)
+		if m.Key != "" {
+			parts = append(parts, keyID(m.Key))
+		}
+	}
+	if a.Mode == ModeData {
+		parts = append(parts, "data")
+	}
+	if a.Type != "" {
+		parts = append(parts, a.Type, a.Name)
+	}
+	if a.Key != "" {
+		parts = append(parts, keyID(a.Key))
+	}
+	return strings.Join(parts, "_")
+}
+
+// keyID returns the unquoted instance key for use in a node ID. Keys other than letters and digits joined by single
+// underscores are followed by the FNV-1a hash of the unquoted key, as the characters that set them apart are
+// replaced or collapsed when the ID is sanitized.
+func keyID(key string) string {
+	key = unquoteKey(key)
+	if idKey.MatchString(key) {
+		return key
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return fmt.Sprintf("%s_%08x", key, h.Sum32())
+}
+
+func (a Address) format(key func(string) string) string {
+	var sb strings.Builder
+	for i, m := range a.Module {
+		if i > 0 {
+			sb.WriteString(".")
+		}
+		sb.WriteString("module." + m.Name)
+		if m.Key != "" {
+			sb.WriteString("[" + key(m.Key) + "]")
+		}
+	}
+	if a.Type == "" {
+		return sb.String()
+	}
+
+	if sb.Len() > 0 {
+		sb.WriteString(".")
+	}
+	if a.Mode == ModeData {
+		sb.WriteString("data.")
+	}
+	sb.WriteString(a.Type + "." + a.Name)
+	if a.Key != "" {
+		sb.WriteString("[" + key(a.Key) + "]")
+	}
+	return sb.String()
+}
+
+// unquoteKey removes the quotes and escapes of a string instance key; other keys are returned unchanged.
+func unquoteKey(key string) string {
+	if len(key) < 2 || key[0] != '"' || key[len(key)-1] != '"' {
+		return key
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(key)-1; i++ {
+		if key[i] == '\\' && i+1 < len(key)-1 {
+			i++
+		}
+		if key[i] != '"' {
+			sb.WriteByte(key[i])
+		}
+	}
+	return sb.String()
+}
+
+// ParseNodeAddress parses a DOT node name or label, which may be quoted and decorated by terraform graph
+// (e.g. `"[root] module.app[\"eu\"].aws_instance.web (expand)"`), as a Terraform address.
+func ParseNodeAddress(s string) (Address, bool) {
+	addr, err := ParseAddress(nodeAnnotations.ReplaceAllString(unquoteDOT(s), ""))
+	return addr, err == nil
+}
+
+// unquoteDOT removes the quotes and escapes of a quoted DOT ID; other IDs are returned unchanged.
+func unquoteDOT(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(s[1 : len(s)-1])
+	}
+	return s
+}
@@ -0,0 +1,190 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Address
+		wantErr bool
+	}{
+		{
+			name:  "managed resource",
+			input: "aws_instance.web",
+			want:  Address{Mode: ModeManaged, Type: "aws_instance", Name: "web"},
+		},
+		{
+			name:  "keyed module and resource",
+			input: `module.app["eu"].aws_instance.web[0]`,
+			want: Address{
+				Module: []ModuleInstance{{Name: "app", Key: `"eu"`}},
+				Mode:   ModeManaged, Type: "aws_instance", Name: "web", Key: "0",
+			},
+		},
+		{
+			name:  "data source with dotted key",
+			input: `data.aws_ami.x["k.v"]`,
+			want:  Address{Mode: ModeData, Type: "aws_ami", Name: "x", Key: `"k.v"`},
+		},
+		{
+			name:  "key with escaped quote and bracket",
+			input: `aws_s3_bucket.b["a\"]b"]`,
+			want:  Address{Mode: ModeManaged, Type: "aws_s3_bucket", Name: "b", Key: `"a\"]b"`},
+		},
+		{
+			name:  "nested modules",
+			input: "module.network.module.subnets[1].data.aws_subnet.main",
+			want: Address{
+				Module: []ModuleInstance{{Name: "network"}, {Name: "subnets", Key: "1"}},
+				Mode:   ModeData, Type: "aws_subnet", Name: "main",
+			},
+		},
+		{
+			name:  "module only",
+			input: `module.app["eu"]`,
+			want:  Address{Module: []ModuleInstance{{Name: "app", Key: `"eu"`}}},
+		},
+		{
+			name:  "bare key from cleaned label",
+			input: "module.app[eu].aws_instance.web",
+			want: Address{
+				Module: []ModuleInstance{{Name: "app", Key: "eu"}},
+				Mode:   ModeManaged, Type: "aws_instance", Name: "web",
+			},
+		},
+		{name: "single segment", input: "invalid", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+		{name: "trailing dot", input: "aws_instance.web.", wantErr: true},
+		{name: "too many segments", input: "aws_instance.web.id", wantErr: true},
+		{name: "keyed type", input: "aws_instance[0].web", wantErr: true},
+		{name: "unterminated key", input: `aws_instance.web["eu]`, wantErr: true},
+		{name: "provider", input: `provider["registry.terraform.io/hashicorp/aws"]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAddress(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidAddress) {
+					t.Errorf("ParseAddress(%q) error = %v, want %v", tt.input, err, errInvalidAddress)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAddress(%q) error = %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAddress(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			if got.String() != tt.input {
+				t.Errorf("ParseAddress(%q).String() = %q", tt.input, got.String())
+			}
+		})
+	}
+}
+
+func TestAddress_ID(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "aws_instance.web", want: "aws_instance_web"},
+		{input: `module.app["eu"].aws_subnet.private[0]`, want: "module_app_eu_aws_subnet_private_0"},
+		{input: `data.aws_ami.x["a_b"]`, want: "data_aws_ami_x_a_b"},
+		{input: `data.aws_ami.x["a.b"]`, want: "data_aws_ami_x_a.b_108bf50c"},
+		{input: `data.aws_ami.x["a-b"]`, want: "data_aws_ami_x_a-b_2a89df63"},
+		{input: `data.aws_ami.x["a__b"]`, want: "data_aws_ami_x_a__b_749bc500"},
+		{input: `module.app["a.b"]`, want: "module_app_a.b_108bf50c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			addr, err := ParseAddress(tt.input)
+			if err != nil {
+				t.Fatalf("ParseAddress(%q) error = %v", tt.input, err)
+			}
+			if got := addr.ID(); got != tt.want {
+				t.Errorf("ParseAddress(%q).ID() = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanLabel_Addresses(t *testing.T) {
+	tests := []struct {
+		name string
+		node string
+		want string
+	}{
+		{name: "terraform graph annotations", node: `"[root] module.app[\"eu\"].aws_instance.web (expand)"`, want: "module.app[eu].aws_instance.web"},
+		{name: "dotted key", node: `"data.aws_ami.x[\"k.v\"]"`, want: "data.aws_ami.x[k.v]"},
+		{name: "key containing an annotation", node: `"aws_instance.web[\"a (close)\"]"`, want: "aws_instance.web[a (close)]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CleanLabel(tt.node); got != tt.want {
+				t.Errorf("CleanLabel(%q) = %q, want %q", tt.node, got, tt.want)
+			}
+		})
+	}
+}
+
+// addressSeeds covers the address grammar: modules, keys of every kind, data sources and malformed input.
+var addressSeeds = []string{
+	"aws_instance.web",
+	`module.app["eu"].aws_instance.web[0]`,
+	`data.aws_ami.x["k.v"]`,
+	`module.a.module.b[1].data.t_x.n["q\"]"]`,
+	"module.app[eu].aws_instance.web",
+	`module.app["eu"]`,
+	`"[root] module.vpc (expand)"`,
+	`provider["registry.terraform.io/hashicorp/aws"]`,
+	"aws_instance.web[",
+	"",
+}
+
+func FuzzParseAddress(f *testing.F) {
+	for _, seed := range addressSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		addr, err := ParseAddress(input)
+		if err != nil {
+			return
+		}
+
+		again, err := ParseAddress(addr.String())
+		if err != nil {
+			t.Fatalf("ParseAddress(%q) ok but String() %q does not parse: %v", input, addr.String(), err)
+		}
+		if !reflect.DeepEqual(addr, again) {
+			t.Fatalf("ParseAddress round trip of %q = %+v, want %+v", input, again, addr)
+		}
+		if addr.Type == "" && len(addr.Module) == 0 {
+			t.Fatalf("ParseAddress(%q) returned an empty address", input)
+		}
+	})
+}
+
+func FuzzCleanLabel(f *testing.F) {
+	for _, seed := range addressSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		if got := CleanLabel(input); strings.Contains(got, `\`) {
+			t.Fatalf("CleanLabel(%q) = %q contains a backslash", input, got)
+		}
+	})
+}
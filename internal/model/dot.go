@@ -0,0 +1,61 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+// providerNodeAddress matches a provider configuration node, optionally inside a module and with an alias,
+// e.g. `module.app.provider["registry.terraform.io/hashicorp/aws"].west`.
+var providerNodeAddress = regexp.MustCompile(`(?:^|\.)provider\["([^"]+)"\](?:\.([A-Za-z_][A-Za-z0-9_-]*))?$`)
+
+// FromDOT converts a graph parsed from terraform graph output. Node IDs are the unquoted DOT node names and labels
+// are cleaned with CleanLabel. Edges to provider configurations have kind EdgeProvider and set the provider of
+// their source node; edges that terraform graph -draw-cycles colors keep their color.
+func FromDOT(dot *gographviz.Graph) *Graph {
+	g := NewGraph()
+	for _, dotNode := range dot.Nodes.Nodes {
+		label := dotNode.Attrs["label"]
+		n := &Node{ID: unquoteDOT(dotNode.Name), Label: CleanLabel(label)}
+		if label == "" {
+			label = dotNode.Name
+		}
+		n.classify(label)
+		n.Action = strings.Trim(dotNode.Attrs[ActionAttr], `"`)
+		g.AddNode(n)
+	}
+
+	for _, dotEdge := range dot.Edges.Edges {
+		edge := &Edge{
+			From:  unquoteDOT(dotEdge.Src),
+			To:    unquoteDOT(dotEdge.Dst),
+			Kind:  EdgeDependency,
+			Color: strings.Trim(dotEdge.Attrs["color"], `"`),
+		}
+		from, to := g.Node(edge.From), g.Node(edge.To)
+		if to != nil && to.Kind == KindProvider {
+			edge.Kind = EdgeProvider
+			if from != nil && from.Provider == "" && from.Kind != KindProvider {
+				from.Provider, from.ProviderAlias = to.Provider, to.ProviderAlias
+			}
+		}
+		g.AddEdge(edge)
+	}
+
+	return g
+}
+
+// parseProviderNode parses a provider configuration node name or label such as
+// `"[root] provider[\"registry.terraform.io/hashicorp/aws\"].west"` into its source address and alias.
+func parseProviderNode(name string) (source, alias string, ok bool) {
+	match := providerNodeAddress.FindStringSubmatch(nodeAnnotations.ReplaceAllString(unquoteDOT(name), ""))
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
@@ -0,0 +1,8 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import "errors"
+
+var errInvalidAddress = errors.New("invalid Terraform address")
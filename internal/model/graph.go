@@ -0,0 +1,253 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// NodeKind classifies the Terraform object a node represents.
+type NodeKind string
+
+// Node kinds.
+const (
+	KindResource NodeKind = "resource"
+	KindData     NodeKind = "data"
+	KindModule   NodeKind = "module"
+	KindProvider NodeKind = "provider"
+	KindVariable NodeKind = "variable"
+	KindLocal    NodeKind = "local"
+	KindOutput   NodeKind = "output"
+	KindMeta     NodeKind = "meta" // Nodes terraform graph adds for its own bookkeeping, e.g. root
+)
+
+// EdgeKind classifies the relationship an edge represents.
+type EdgeKind string
+
+// Edge kinds.
+const (
+	EdgeDependency EdgeKind = "dependency" // The source depends on the target
+	EdgeProvider   EdgeKind = "provider"   // The source is managed by the target provider configuration
+)
+
+// referenceKinds maps the first segment of addresses such as var.region to the kind of object they name.
+var referenceKinds = map[string]NodeKind{
+	"var":    KindVariable,
+	"local":  KindLocal,
+	"output": KindOutput,
+}
+
+// Graph is a Terraform dependency graph. Nodes and edges keep the order in which they were added.
+type Graph struct {
+	Nodes []*Node
+	Edges []*Edge
+	// TerraformVersion is the version of Terraform or OpenTofu that produced the graph's source, when known.
+	TerraformVersion string
+	index            map[string]*Node
+}
+
+// Node is an object in the graph.
+type Node struct {
+	ID      string // Unique within the graph: the DOT node name or the address the node was built from
+	Label   string // Human-friendly label, see CleanLabel; empty for nodes that are never drawn
+	Kind    NodeKind
+	Address Address // Parsed address; zero for provider and meta nodes
+	// Provider is the source address of the provider configuration managing the node, e.g.
+	// registry.terraform.io/hashicorp/aws, and ProviderAlias its alias. Both are empty when unknown.
+	Provider      string
+	ProviderAlias string
+	Source        *SourceLocation // Where the object is declared, when known
+	Attributes    map[string]any  // Planned or recorded attribute values, when known
+	Action        string          // Planned change action, see the Action constants; empty when not planned
+}
+
+// SourceLocation is a position in a Terraform configuration file.
+type SourceLocation struct {
+	Filename string
+	Line     int
+}
+
+// Edge is a directed relationship between two nodes, identified by their IDs.
+type Edge struct {
+	From  string
+	To    string
+	Kind  EdgeKind
+	Label string // Optional description of the relationship
+	Color string // Color terraform graph -draw-cycles gives edges that are part of a dependency cycle
+	// References records how the dependent refers to the dependency, when known from plan configuration or
+	// static analysis.
+	References []Reference
+}
+
+// Reference is an argument of a dependent object that refers to its dependency.
+type Reference struct {
+	From string // Argument of the dependent, e.g. vpc_id or depends_on; empty when unknown
+	To   string // Attribute of the dependency, e.g. id; empty when the whole object is referenced
+}
+
+// NewGraph returns an empty graph.
+func NewGraph() *Graph {
+	return &Graph{index: make(map[string]*Node)}
+}
+
+// NewNode returns a node for a DOT node name, address or label. Its label, kind and address are derived from
+// name, so `"[root] module.app.aws_instance.web (expand)"` becomes a resource labelled module.app.aws_instance.web.
+func NewNode(name string) *Node {
+	n := &Node{ID: name, Label: CleanLabel(name)}
+	n.classify(name)
+	return n
+}
+
+// classify sets the node's kind and address from s, which is a DOT node name, address or label.
+func (n *Node) classify(s string) {
+	if source, alias, ok := parseProviderNode(s); ok {
+		n.Kind = KindProvider
+		n.Provider = source
+		n.ProviderAlias = alias
+		return
+	}
+
+	addr, ok := ParseNodeAddress(s)
+	if !ok {
+		n.Kind = KindMeta
+		return
+	}
+	n.Address = addr
+
+	switch {
+	case addr.Type == "":
+		n.Kind = KindModule
+	case addr.Mode == ModeData:
+		n.Kind = KindData
+	case referenceKinds[addr.Type] != "":
+		n.Kind = referenceKinds[addr.Type]
+	default:
+		n.Kind = KindResource
+	}
+}
+
+// AddNode adds n to the graph and returns it. If a node with the same ID exists, that node is returned instead.
+func (g *Graph) AddNode(n *Node) *Node {
+	if existing, ok := g.index[n.ID]; ok {
+		return existing
+	}
+	if g.index == nil {
+		g.index = make(map[string]*Node)
+	}
+	g.index[n.ID] = n
+	g.Nodes = append(g.Nodes, n)
+	return n
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id string) *Node {
+	return g.index[id]
+}
+
+// AddEdge appends e to the graph.
+func (g *Graph) AddEdge(e *Edge) {
+	g.Edges = append(g.Edges, e)
+}
+
+// Builder accumulates nodes and dependency edges for inputs other than terraform graph, such as plans, state and
+// static configuration. Duplicate nodes and edges are ignored.
+type Builder struct {
+	graph *Graph
+	edges map[[2]string]map[Reference]bool
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{graph: NewGraph(), edges: make(map[[2]string]map[Reference]bool)}
+}
+
+// AddNode adds a node named after address and returns it, or returns the existing node for address.
+func (b *Builder) AddNode(address string) *Node {
+	return b.graph.AddNode(NewNode(address))
+}
+
+// HasNode reports whether a node for address has been added.
+func (b *Builder) HasNode(address string) bool {
+	return b.graph.Node(address) != nil
+}
+
+// AddDependency records a dependency edge from the dependent address to its dependency. Self-references,
+// duplicates and edges to unknown nodes are ignored.
+func (b *Builder) AddDependency(from, to string) {
+	if from == to || !b.HasNode(from) || !b.HasNode(to) {
+		return
+	}
+	if b.edges[[2]string{from, to}] == nil {
+		b.edges[[2]string{from, to}] = make(map[Reference]bool)
+	}
+}
+
+// AddReference records a dependency edge like AddDependency, together with the reference that causes it.
+func (b *Builder) AddReference(from, to string, ref Reference) {
+	b.AddDependency(from, to)
+	if refs := b.edges[[2]string{from, to}]; refs != nil {
+		refs[ref] = true
+	}
+}
+
+// Graph adds the recorded edges in a stable order and returns the graph.
+func (b *Builder) Graph() *Graph {
+	edges := make([][2]string, 0, len(b.edges))
+	for edge := range b.edges {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+
+	b.graph.Edges = b.graph.Edges[:0]
+	for _, edge := range edges {
+		b.graph.AddEdge(&Edge{From: edge[0], To: edge[1], Kind: EdgeDependency, References: sortedReferences(b.edges[edge])})
+	}
+
+	return b.graph
+}
+
+// sortedReferences returns refs sorted, leaving out whole-object references from arguments that also refer to
+// an attribute of the same object: Terraform records both aws_vpc.main and aws_vpc.main.id for vpc_id.
+func sortedReferences(refs map[Reference]bool) []Reference {
+	var out []Reference
+	for ref := range refs {
+		if ref.To == "" && hasAttributeReference(refs, ref.From) {
+			continue
+		}
+		out = append(out, ref)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].From != out[j].From {
+			return out[i].From < out[j].From
+		}
+		return out[i].To < out[j].To
+	})
+	return out
+}
+
+func hasAttributeReference(refs map[Reference]bool, from string) bool {
+	for ref := range refs {
+		if ref.From == from && ref.To != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ModulePath returns the dot-joined module names of the node's address, e.g. "network.subnets".
+func (n *Node) ModulePath() string {
+	return n.Address.ModulePath()
+}
+
+// ProviderType returns the type of the node's provider, i.e. the last segment of its source address, or "" when
+// the provider is unknown.
+func (n *Node) ProviderType() string {
+	return n.Provider[strings.LastIndex(n.Provider, "/")+1:]
+}
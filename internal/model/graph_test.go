@@ -0,0 +1,163 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/awalterschulze/gographviz"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestNewNode(t *testing.T) {
+	tests := []struct {
+		name      string
+		wantKind  NodeKind
+		wantLabel string
+	}{
+		{`"[root] module.app.aws_instance.web (expand)"`, KindResource, "module.app.aws_instance.web"},
+		{`data.aws_ami.ubuntu`, KindData, "data.aws_ami.ubuntu"},
+		{`module.app["eu"]`, KindModule, "module.app[eu]"},
+		{`provider["registry.terraform.io/hashicorp/aws"].west`, KindProvider, "provider: registry.terraform.io/hashicorp/aws.west"},
+		{`var.region`, KindVariable, "var.region"},
+		{`module.app.local.name`, KindLocal, "module.app.local.name"},
+		{`output.id`, KindOutput, "output.id"},
+		{`"[root] root"`, KindMeta, "root"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewNode(tt.name)
+			if n.Kind != tt.wantKind || n.Label != tt.wantLabel {
+				t.Errorf("NewNode(%q) = kind %q, label %q; want %q, %q", tt.name, n.Kind, n.Label, tt.wantKind, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestFromDOT(t *testing.T) {
+	ast, err := gographviz.ParseString(`digraph G {
+  "[root] aws_instance.web (expand)" [label = "aws_instance.web", comment = "create"];
+  "[root] provider[\"registry.terraform.io/hashicorp/aws\"].west" [label = "provider[\"registry.terraform.io/hashicorp/aws\"].west"];
+  "[root] var.region" [label = "var.region"];
+  "[root] aws_instance.web (expand)" -> "[root] provider[\"registry.terraform.io/hashicorp/aws\"].west";
+  "[root] aws_instance.web (expand)" -> "[root] var.region" [color = "red"];
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot := gographviz.NewGraph()
+	if err := gographviz.Analyse(ast, dot); err != nil {
+		t.Fatal(err)
+	}
+
+	g := FromDOT(dot)
+	web := g.Node("[root] aws_instance.web (expand)")
+	if web == nil {
+		t.Fatalf("FromDOT() has no aws_instance.web node: %+v", g.Nodes)
+	}
+	if web.Kind != KindResource || web.Action != ActionCreate {
+		t.Errorf("aws_instance.web = kind %q, action %q", web.Kind, web.Action)
+	}
+	if web.Provider != "registry.terraform.io/hashicorp/aws" || web.ProviderAlias != "west" || web.ProviderType() != "aws" {
+		t.Errorf("aws_instance.web provider = %q alias %q", web.Provider, web.ProviderAlias)
+	}
+	if len(g.Edges) != 2 {
+		t.Fatalf("FromDOT() edges = %d, want 2", len(g.Edges))
+	}
+	for _, e := range g.Edges {
+		switch e.To {
+		case `[root] provider["registry.terraform.io/hashicorp/aws"].west`:
+			if e.Kind != EdgeProvider {
+				t.Errorf("provider edge kind = %q", e.Kind)
+			}
+		case "[root] var.region":
+			if e.Kind != EdgeDependency || e.Color != "red" {
+				t.Errorf("dependency edge = %+v", e)
+			}
+		}
+	}
+}
+
+func TestFromPlan(t *testing.T) {
+	plan := &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			{
+				Address: "aws_vpc.main", Mode: tfjson.ManagedResourceMode, Type: "aws_vpc", Name: "main",
+				ProviderName: "registry.terraform.io/hashicorp/aws",
+				Change:       &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}, After: map[string]any{"cidr_block": "10.0.0.0/16"}},
+			},
+			{
+				Address: "aws_eip.old", Mode: tfjson.ManagedResourceMode, Type: "aws_eip", Name: "old",
+				ProviderName: "registry.terraform.io/hashicorp/aws",
+				Change:       &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionDelete}, Before: map[string]any{"domain": "vpc"}},
+			},
+		},
+	}
+
+	g := FromPlan(plan)
+	vpc, eip := g.Node("aws_vpc.main"), g.Node("aws_eip.old")
+	if vpc == nil || eip == nil {
+		t.Fatalf("FromPlan() nodes = %+v", g.Nodes)
+	}
+	if vpc.Action != ActionCreate || vpc.Provider != "registry.terraform.io/hashicorp/aws" || vpc.Attributes["cidr_block"] != "10.0.0.0/16" {
+		t.Errorf("aws_vpc.main = %+v", vpc)
+	}
+	if eip.Action != ActionDelete || eip.Attributes["domain"] != "vpc" {
+		t.Errorf("aws_eip.old = %+v, want the attributes before deletion", eip)
+	}
+}
+
+func TestFromState(t *testing.T) {
+	state := &State{
+		Version: 4,
+		Resources: []StateResource{
+			{
+				Mode: "managed", Type: "aws_vpc", Name: "main", Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+				Instances: []StateInstance{{Attributes: map[string]any{"id": "vpc-1"}}},
+			},
+			{
+				Module: `module.app["eu"]`, Mode: "managed", Type: "aws_subnet", Name: "private",
+				Provider:  `module.app.provider["registry.terraform.io/hashicorp/aws"].west`,
+				Instances: []StateInstance{{IndexKey: float64(0), Dependencies: []string{"aws_vpc.main"}}},
+			},
+		},
+	}
+
+	g := FromState(state)
+	subnet := g.Node(`module.app["eu"].aws_subnet.private[0]`)
+	if subnet == nil {
+		t.Fatalf("FromState() nodes = %+v", g.Nodes)
+	}
+	if subnet.Kind != KindResource || subnet.ModulePath() != "app" || subnet.ProviderAlias != "west" {
+		t.Errorf("subnet = %+v", subnet)
+	}
+	if vpc := g.Node("aws_vpc.main"); vpc == nil || vpc.Attributes["id"] != "vpc-1" {
+		t.Errorf("aws_vpc.main = %+v", vpc)
+	}
+	if len(g.Edges) != 1 || g.Edges[0].To != "aws_vpc.main" || g.Edges[0].Kind != EdgeDependency {
+		t.Errorf("FromState() edges = %+v", g.Edges)
+	}
+}
+
+func TestBuilder_AddReference(t *testing.T) {
+	b := NewBuilder()
+	b.AddNode("aws_subnet.private")
+	b.AddNode("aws_vpc.main")
+	b.AddReference("aws_subnet.private", "aws_vpc.main", Reference{From: "vpc_id", To: "id"})
+	b.AddReference("aws_subnet.private", "aws_vpc.main", Reference{From: "vpc_id"})
+	b.AddReference("aws_subnet.private", "aws_vpc.main", Reference{From: "depends_on"})
+	b.AddReference("aws_subnet.private", "aws_vpc.main", Reference{From: "cidr_block", To: "cidr_block"})
+	b.AddReference("aws_subnet.private", "aws_missing.x", Reference{From: "vpc_id", To: "id"})
+
+	g := b.Graph()
+	if len(g.Edges) != 1 {
+		t.Fatalf("Graph() edges = %+v", g.Edges)
+	}
+	want := []Reference{{From: "cidr_block", To: "cidr_block"}, {From: "depends_on"}, {From: "vpc_id", To: "id"}}
+	if !slices.Equal(g.Edges[0].References, want) {
+		t.Errorf("edge references = %+v, want %+v", g.Edges[0].References, want)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// labelCleaner matches the annotations terraform graph adds to node names and the quotes around them.
+var labelCleaner = regexp.MustCompile(`\s*\(expand\)|\s*\(close\)|\[root\]\s*|"`)
+
+// StripAnnotations removes `[root]`, `(expand)`, `(close)` and double quotes from s.
+func StripAnnotations(s string) string {
+	return labelCleaner.ReplaceAllString(s, "")
+}
+
+// CleanLabel returns a human-friendly node label suitable for rendering.
+// Terraform addresses are parsed with ParseAddress and rendered without quotes in their instance keys. Other labels have inline
+// annotations and quotes removed, and `provider[...]` is converted to `provider: ...`. Backslashes are always stripped.
+func CleanLabel(label string) string {
+	if addr, ok := ParseNodeAddress(label); ok {
+		label = addr.Label()
+	} else {
+		label = StripAnnotations(label)
+	}
+	if strings.HasPrefix(label, "provider[") {
+		label = strings.ReplaceAll(label, "[", ": ")
+		label = strings.ReplaceAll(label, "]", "")
+	}
+	label = strings.ReplaceAll(label, "\\", "")
+	return label
+}
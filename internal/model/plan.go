@@ -0,0 +1,345 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// Plan actions attached to nodes built from plan JSON.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReplace = "replace"
+	ActionRead    = "read"
+	ActionNoOp    = "no-op"
+	ActionForget  = "forget"
+)
+
+// ActionAttr is the Graphviz attribute that carries a node's plan action in DOT graphs.
+const ActionAttr = "comment"
+
+// maxReferenceDepth bounds how far references are followed through module inputs and outputs.
+const maxReferenceDepth = 32
+
+// moduleInstanceKey matches the instance key of a module address segment, e.g. `["eu"]` or `[0]`.
+var moduleInstanceKey = regexp.MustCompile(`\[(?:"(?:[^"\\]|\\.)*"|[0-9]+)\]`)
+
+// FromPlan builds a graph from a JSON plan. Nodes are created for every entry in resource_changes and carry the
+// planned action, the provider and the planned attribute values (the prior values for deletions) with sensitive
+// values masked; edges follow the references and depends_on recorded in the plan's configuration, including
+// references that pass through module inputs and outputs.
+func FromPlan(plan *tfjson.Plan) *Graph {
+	b := NewBuilder()
+	r := &planResolver{builder: b, instances: make(map[string][]string)}
+	for _, rc := range plan.ResourceChanges {
+		if rc == nil || rc.Address == "" {
+			continue
+		}
+		n := b.AddNode(rc.Address)
+		n.Provider = rc.ProviderName
+		if rc.Change != nil {
+			n.Action = PlanAction(rc.Change.Actions)
+			n.Attributes = plannedAttributes(rc.Change)
+		}
+		configAddr := ConfigAddress(rc.ModuleAddress, rc.Mode == tfjson.DataResourceMode, rc.Type, rc.Name)
+		r.instances[configAddr] = append(r.instances[configAddr], rc.Address)
+	}
+
+	if plan.Config != nil && plan.Config.RootModule != nil {
+		r.walk(&planScope{module: plan.Config.RootModule}, nil)
+	}
+
+	graph := b.Graph()
+	graph.TerraformVersion = plan.TerraformVersion
+	return graph
+}
+
+// plannedAttributes returns the attribute values a change plans, or the prior values when the object is deleted.
+// Values the plan marks as sensitive are replaced by SensitiveValue.
+func plannedAttributes(change *tfjson.Change) map[string]any {
+	values, sensitive := change.After, change.AfterSensitive
+	if values == nil {
+		values, sensitive = change.Before, change.BeforeSensitive
+	}
+	attrs, _ := values.(map[string]any)
+	return maskAttributes(attrs, sensitive)
+}
+
+// PlanAction collapses a plan's action list into a single action name.
+func PlanAction(actions tfjson.Actions) string {
+	switch {
+	case actions.Replace():
+		return ActionReplace
+	case actions.Create():
+		return ActionCreate
+	case actions.Delete():
+		return ActionDelete
+	case actions.Update():
+		return ActionUpdate
+	case actions.Read():
+		return ActionRead
+	case actions.Forget():
+		return ActionForget
+	default:
+		return ActionNoOp
+	}
+}
+
+// ConfigAddress returns the configuration address of a resource, i.e. its address with all module and
+// resource instance keys removed.
+func ConfigAddress(moduleAddress string, data bool, resourceType, name string) string {
+	var sb strings.Builder
+	if moduleAddress != "" {
+		sb.WriteString(moduleInstanceKey.ReplaceAllString(moduleAddress, ""))
+		sb.WriteString(".")
+	}
+	if data {
+		sb.WriteString("data.")
+	}
+	sb.WriteString(resourceType + "." + name)
+	return sb.String()
+}
+
+// planScope is a module in the plan configuration together with the call that instantiated it.
+type planScope struct {
+	prefix string
+	module *tfjson.ConfigModule
+	parent *planScope
+	call   *tfjson.ModuleCall
+}
+
+func (s *planScope) child(name string) *planScope {
+	call := s.module.ModuleCalls[name]
+	if call == nil || call.Module == nil {
+		return nil
+	}
+	return &planScope{
+		prefix: s.prefix + "module." + name + ".",
+		module: call.Module,
+		parent: s,
+		call:   call,
+	}
+}
+
+// planResolver resolves configuration references in a plan to the resource instances they point at.
+type planResolver struct {
+	builder *Builder
+	// instances maps a configuration address to the instance addresses present in resource_changes.
+	instances map[string][]string
+}
+
+// planTarget is a resource that a configuration argument refers to.
+type planTarget struct {
+	address   string // Configuration address, or a module prefix ending in "."
+	argument  string // Argument of the referring resource, e.g. vpc_id
+	attribute string // Attribute of the target, e.g. id; empty when the whole object is referenced
+}
+
+// walk adds edges for every resource in scope and its child modules. inherited holds the targets of
+// depends_on arguments on enclosing module calls.
+func (r *planResolver) walk(scope *planScope, inherited []planTarget) {
+	for _, res := range scope.module.Resources {
+		targets := append([]planTarget{}, inherited...)
+		for _, arg := range resourceArguments(res) {
+			for _, ref := range arg.references {
+				targets = append(targets, withArgument(r.resolve(scope, ref, 0), arg.name)...)
+			}
+		}
+		for _, dep := range res.DependsOn {
+			targets = append(targets, r.resolveDependsOn(scope, dep)...)
+		}
+		r.connect(scope.prefix+res.Address, targets)
+	}
+
+	for name, call := range scope.module.ModuleCalls {
+		child := scope.child(name)
+		if child == nil {
+			continue
+		}
+		childInherited := append([]planTarget{}, inherited...)
+		for _, dep := range call.DependsOn {
+			childInherited = append(childInherited, r.resolveDependsOn(scope, dep)...)
+		}
+		r.walk(child, childInherited)
+	}
+}
+
+// connect adds edges from every instance of the resource at configAddr to every instance of each target.
+// Targets ending in "." are module prefixes and match every resource inside that module.
+func (r *planResolver) connect(configAddr string, targets []planTarget) {
+	for _, from := range r.instances[configAddr] {
+		for _, target := range targets {
+			for _, to := range r.targetInstances(target.address) {
+				r.builder.AddReference(from, to, Reference{From: target.argument, To: target.attribute})
+			}
+		}
+	}
+}
+
+func (r *planResolver) targetInstances(target string) []string {
+	if !strings.HasSuffix(target, ".") {
+		return r.instances[target]
+	}
+
+	var out []string
+	for configAddr, instances := range r.instances {
+		if strings.HasPrefix(configAddr, target) {
+			out = append(out, instances...)
+		}
+	}
+	return out
+}
+
+func (r *planResolver) resolveDependsOn(scope *planScope, dep string) []planTarget {
+	parts := referenceParts(dep)
+	if len(parts) == 2 && parts[0] == "module" {
+		return []planTarget{{address: scope.prefix + "module." + parts[1] + ".", argument: "depends_on"}}
+	}
+	targets := withArgument(r.resolve(scope, dep, 0), "depends_on")
+	for i := range targets {
+		targets[i].attribute = ""
+	}
+	return targets
+}
+
+// resolve returns the resources that ref ultimately refers to, following input variables up to the calling
+// module and module outputs down into the called module. The targets have no argument set.
+func (r *planResolver) resolve(scope *planScope, ref string, depth int) []planTarget {
+	if scope == nil || depth > maxReferenceDepth {
+		return nil
+	}
+
+	parts := referenceParts(ref)
+	if len(parts) < 2 {
+		return nil
+	}
+
+	switch parts[0] {
+	case "var":
+		if scope.call == nil {
+			return nil
+		}
+		return r.resolveAll(scope.parent, expressionReferences(scope.call.Expressions[parts[1]]), depth)
+	case "module":
+		return r.resolveModuleOutput(scope, parts, depth)
+	case "data":
+		if len(parts) < 3 {
+			return nil
+		}
+		return []planTarget{{address: scope.prefix + "data." + parts[1] + "." + parts[2], attribute: partAt(parts, 3)}}
+	case "local", "each", "count", "path", "self", "terraform":
+		return nil
+	default:
+		return []planTarget{{address: scope.prefix + parts[0] + "." + parts[1], attribute: partAt(parts, 2)}}
+	}
+}
+
+func (r *planResolver) resolveModuleOutput(scope *planScope, parts []string, depth int) []planTarget {
+	child := scope.child(parts[1])
+	if child == nil {
+		return nil
+	}
+
+	var refs []string
+	for name, output := range child.module.Outputs {
+		if output == nil || (len(parts) > 2 && parts[2] != name) {
+			continue
+		}
+		refs = append(refs, expressionReferences(output.Expression)...)
+	}
+	return r.resolveAll(child, refs, depth)
+}
+
+func (r *planResolver) resolveAll(scope *planScope, refs []string, depth int) []planTarget {
+	var out []planTarget
+	for _, ref := range refs {
+		out = append(out, r.resolve(scope, ref, depth+1)...)
+	}
+	return out
+}
+
+// withArgument sets the referring argument of targets and returns them.
+func withArgument(targets []planTarget, argument string) []planTarget {
+	for i := range targets {
+		targets[i].argument = argument
+	}
+	return targets
+}
+
+// partAt returns parts[i], or "" when parts is shorter.
+func partAt(parts []string, i int) string {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return ""
+}
+
+// referenceParts splits a reference such as `aws_instance.web[0].id` into its dot-separated
+// segments with any instance keys removed.
+func referenceParts(ref string) []string {
+	parts := strings.Split(ref, ".")
+	for i, part := range parts {
+		if idx := strings.Index(part, "["); idx >= 0 {
+			parts[i] = part[:idx]
+		}
+	}
+	return parts
+}
+
+// planArgument is an argument of a configuration resource and the references in its expression.
+type planArgument struct {
+	name       string // Argument name; arguments of nested blocks are prefixed with the block type, e.g. ingress.cidr_blocks
+	references []string
+}
+
+// resourceArguments returns every argument of a configuration resource that can reference another object,
+// sorted by name.
+func resourceArguments(res *tfjson.ConfigResource) []planArgument {
+	var args []planArgument
+	for name, expr := range res.Expressions {
+		args = appendArguments(args, name, expr)
+	}
+	args = appendArguments(args, "count", res.CountExpression)
+	args = appendArguments(args, "for_each", res.ForEachExpression)
+	sort.Slice(args, func(i, j int) bool { return args[i].name < args[j].name })
+	return args
+}
+
+// appendArguments appends the argument name with the references of expr, or the arguments of expr's nested
+// blocks prefixed with name.
+func appendArguments(args []planArgument, name string, expr *tfjson.Expression) []planArgument {
+	if expr == nil || expr.ExpressionData == nil {
+		return args
+	}
+	if len(expr.References) > 0 {
+		args = append(args, planArgument{name: name, references: expr.References})
+	}
+	for _, block := range expr.NestedBlocks {
+		for nestedName, nested := range block {
+			args = appendArguments(args, name+"."+nestedName, nested)
+		}
+	}
+	return args
+}
+
+// expressionReferences returns the references of expr and any nested block expressions.
+func expressionReferences(expr *tfjson.Expression) []string {
+	if expr == nil || expr.ExpressionData == nil {
+		return nil
+	}
+
+	refs := append([]string{}, expr.References...)
+	for _, block := range expr.NestedBlocks {
+		for _, nested := range block {
+			refs = append(refs, expressionReferences(nested)...)
+		}
+	}
+	return refs
+}
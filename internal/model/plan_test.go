@@ -0,0 +1,67 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestPlanAction(t *testing.T) {
+	tests := []struct {
+		actions tfjson.Actions
+		want    string
+	}{
+		{tfjson.Actions{tfjson.ActionCreate}, ActionCreate},
+		{tfjson.Actions{tfjson.ActionUpdate}, ActionUpdate},
+		{tfjson.Actions{tfjson.ActionDelete}, ActionDelete},
+		{tfjson.Actions{tfjson.ActionDelete, tfjson.ActionCreate}, ActionReplace},
+		{tfjson.Actions{tfjson.ActionCreate, tfjson.ActionDelete}, ActionReplace},
+		{tfjson.Actions{tfjson.ActionRead}, ActionRead},
+		{tfjson.Actions{tfjson.ActionNoop}, ActionNoOp},
+	}
+
+	for _, tt := range tests {
+		if got := PlanAction(tt.actions); got != tt.want {
+			t.Errorf("PlanAction(%v) = %q, want %q", tt.actions, got, tt.want)
+		}
+	}
+}
+
+func TestPlannedAttributes(t *testing.T) {
+	change := &tfjson.Change{
+		Before: map[string]any{"password": "old"},
+		After: map[string]any{
+			"instance_type": "t3.micro",
+			"password":      "hunter2",
+			"tags":          map[string]any{"env": "prod", "token": "abc"},
+			"users":         []any{"alice", "bob"},
+		},
+		AfterSensitive: map[string]any{
+			"password": true,
+			"tags":     map[string]any{"token": true},
+			"users":    []any{false, true},
+		},
+	}
+
+	attrs := plannedAttributes(change)
+	if attrs["instance_type"] != "t3.micro" || attrs["password"] != SensitiveValue {
+		t.Errorf("plannedAttributes() = %v", attrs)
+	}
+	if tags := attrs["tags"].(map[string]any); tags["env"] != "prod" || tags["token"] != SensitiveValue {
+		t.Errorf("plannedAttributes() tags = %v", tags)
+	}
+	if users := attrs["users"].([]any); users[0] != "alice" || users[1] != SensitiveValue {
+		t.Errorf("plannedAttributes() users = %v", users)
+	}
+	if change.After.(map[string]any)["password"] != "hunter2" {
+		t.Error("plannedAttributes() modified the plan")
+	}
+
+	deleted := &tfjson.Change{Before: map[string]any{"password": "old"}, BeforeSensitive: true}
+	if attrs := plannedAttributes(deleted); attrs != nil {
+		t.Errorf("plannedAttributes() of a sensitive object = %v, want nil", attrs)
+	}
+}
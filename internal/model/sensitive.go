@@ -0,0 +1,110 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// SensitiveValue replaces attribute values that the plan or state marks as sensitive.
+const SensitiveValue = "(sensitive value)"
+
+// maskSensitive returns a copy of value with every part that sensitive marks replaced by SensitiveValue.
+// sensitive has the shape of a plan's after_sensitive: true marks the value itself, and objects and lists mark
+// their elements. Lists may also be marked by objects keyed by the decimal index.
+func maskSensitive(value any, sensitive any) any {
+	switch s := sensitive.(type) {
+	case bool:
+		if s && value != nil {
+			return SensitiveValue
+		}
+	case map[string]any:
+		switch v := value.(type) {
+		case map[string]any:
+			masked := make(map[string]any, len(v))
+			for k, elem := range v {
+				masked[k] = maskSensitive(elem, s[k])
+			}
+			return masked
+		case []any:
+			masked := make([]any, len(v))
+			for i, elem := range v {
+				masked[i] = maskSensitive(elem, s[strconv.Itoa(i)])
+			}
+			return masked
+		}
+	case []any:
+		if v, ok := value.([]any); ok {
+			masked := make([]any, len(v))
+			for i, elem := range v {
+				if i < len(s) {
+					elem = maskSensitive(elem, s[i])
+				}
+				masked[i] = elem
+			}
+			return masked
+		}
+	}
+	return value
+}
+
+// maskAttributes returns attrs with the values sensitive marks replaced by SensitiveValue, or nil when the
+// whole object is sensitive.
+func maskAttributes(attrs map[string]any, sensitive any) map[string]any {
+	if attrs == nil || sensitive == nil {
+		return attrs
+	}
+	masked, _ := maskSensitive(attrs, sensitive).(map[string]any)
+	return masked
+}
+
+// statePathStep is one step of a path in a state instance's sensitive_attributes.
+type statePathStep struct {
+	Type  string `json:"type"` // get_attr or index
+	Value any    `json:"value"`
+}
+
+// sensitivePaths converts the sensitive_attributes of a state instance, a list of attribute paths, into the
+// after_sensitive shape accepted by maskSensitive. It returns nil when raw is empty or not a list of paths.
+func sensitivePaths(raw json.RawMessage) any {
+	var paths [][]statePathStep
+	if len(raw) == 0 || json.Unmarshal(raw, &paths) != nil {
+		return nil
+	}
+
+	tree := make(map[string]any)
+	for _, path := range paths {
+		node := tree
+		for i, step := range path {
+			key := pathKey(step.Value)
+			if i == len(path)-1 {
+				node[key] = true
+				break
+			}
+			child, ok := node[key].(map[string]any)
+			if !ok {
+				if node[key] == true {
+					break
+				}
+				child = make(map[string]any)
+				node[key] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// pathKey formats a path step value as a key of the after_sensitive shape.
+func pathKey(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// State is the subset of the Terraform v4 state format needed to build a graph.
+type State struct {
+	Version          int             `json:"version"`
+	TerraformVersion string          `json:"terraform_version"`
+	Resources        []StateResource `json:"resources"`
+}
+
+// StateResource is a resource in Terraform state together with its instances.
+type StateResource struct {
+	Module    string          `json:"module"`
+	Mode      string          `json:"mode"`
+	Type      string          `json:"type"`
+	Name      string          `json:"name"`
+	Provider  string          `json:"provider"`
+	Instances []StateInstance `json:"instances"`
+}
+
+// StateInstance is one instance of a resource in Terraform state.
+type StateInstance struct {
+	IndexKey            any             `json:"index_key"`
+	Attributes          map[string]any  `json:"attributes"`
+	SensitiveAttributes json.RawMessage `json:"sensitive_attributes"` // Paths of sensitive attribute values
+	Dependencies        []string        `json:"dependencies"`
+}
+
+// FromState builds a graph from Terraform state. Every managed and data resource instance becomes a node carrying
+// its provider and recorded attributes, with sensitive values masked, and the dependencies recorded on each
+// instance become edges to every instance of the dependency.
+func FromState(state *State) *Graph {
+	b := NewBuilder()
+
+	// instances maps a resource's configuration address to the addresses of its instances.
+	instances := make(map[string][]string)
+	for _, res := range state.Resources {
+		configAddr := ConfigAddress(res.Module, res.Mode == "data", res.Type, res.Name)
+		source, alias, _ := parseProviderNode(res.Provider)
+		for _, inst := range res.Instances {
+			address := res.instancePrefix() + InstanceKey(inst.IndexKey)
+			n := b.AddNode(address)
+			n.Provider, n.ProviderAlias = source, alias
+			n.Attributes = maskAttributes(inst.Attributes, sensitivePaths(inst.SensitiveAttributes))
+			instances[configAddr] = append(instances[configAddr], address)
+		}
+	}
+
+	for _, res := range state.Resources {
+		for _, inst := range res.Instances {
+			from := res.instancePrefix() + InstanceKey(inst.IndexKey)
+			for _, dep := range inst.Dependencies {
+				for _, to := range instances[dep] {
+					b.AddDependency(from, to)
+				}
+			}
+		}
+	}
+
+	graph := b.Graph()
+	graph.TerraformVersion = state.TerraformVersion
+	return graph
+}
+
+// instancePrefix returns the resource's address including its module instance path but without an instance key.
+func (r StateResource) instancePrefix() string {
+	prefix := ""
+	if r.Module != "" {
+		prefix = r.Module + "."
+	}
+	if r.Mode == "data" {
+		prefix += "data."
+	}
+	return prefix + r.Type + "." + r.Name
+}
+
+// InstanceKey formats a state index_key as an address suffix, e.g. `[0]` or `["eu"]`.
+func InstanceKey(key any) string {
+	switch k := key.(type) {
+	case nil:
+		return ""
+	case float64:
+		return "[" + strconv.FormatFloat(k, 'f', -1, 64) + "]"
+	case string:
+		return "[" + strconv.Quote(k) + "]"
+	default:
+		return fmt.Sprintf("[%v]", k)
+	}
+}
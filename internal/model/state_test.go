@@ -0,0 +1,46 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInstanceKey(t *testing.T) {
+	tests := []struct {
+		key  any
+		want string
+	}{
+		{nil, ""},
+		{float64(0), "[0]"},
+		{float64(12), "[12]"},
+		{"eu", `["eu"]`},
+		{"k.v", `["k.v"]`},
+	}
+
+	for _, tt := range tests {
+		if got := InstanceKey(tt.key); got != tt.want {
+			t.Errorf("InstanceKey(%v) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSensitivePaths(t *testing.T) {
+	raw := json.RawMessage(`[[{"type":"get_attr","value":"password"}],[{"type":"get_attr","value":"users"},{"type":"index","value":1}]]`)
+	attrs := maskAttributes(map[string]any{"id": "db-1", "password": "hunter2", "users": []any{"alice", "bob"}}, sensitivePaths(raw))
+	if attrs["id"] != "db-1" || attrs["password"] != SensitiveValue {
+		t.Errorf("maskAttributes() = %v", attrs)
+	}
+	if users := attrs["users"].([]any); users[0] != "alice" || users[1] != SensitiveValue {
+		t.Errorf("maskAttributes() users = %v", users)
+	}
+
+	for _, raw := range []string{"", "[]", `{"unexpected":true}`} {
+		attrs := maskAttributes(map[string]any{"id": "db-1"}, sensitivePaths(json.RawMessage(raw)))
+		if attrs["id"] != "db-1" {
+			t.Errorf("maskAttributes() with sensitive_attributes %q = %v", raw, attrs)
+		}
+	}
+}
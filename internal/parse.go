@@ -5,66 +5,143 @@ package internal
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
 
+	"github.com/RoseSecurity/terramaid/internal/model"
 	"github.com/RoseSecurity/terramaid/pkg/utils"
 	"github.com/awalterschulze/gographviz"
 	"github.com/hashicorp/terraform-exec/tfexec"
 )
 
-const emptyGraph = `digraph G {
-  rankdir = "RL";
-  node [shape = rect, fontname = "sans-serif"];
-  /* This configuration does not contain any resources.         */
-  /* For a more detailed graph, try: terraform graph -type=plan */
-}
-`
+// stdinPath is the conventional path used to read input from stdin.
+const stdinPath = "-"
 
-// ParseTerraform parses the Terraform plan and returns the generated graph.
-func ParseTerraform(ctx context.Context, workingDir, tfPath, planFile string, verbose bool) (*gographviz.Graph, error) {
-	if verbose {
-		utils.LogVerbose("Initializing Terraform with working directory: %s", workingDir)
-		utils.LogVerbose("Using Terraform binary: %s", tfPath)
+// ParseTerraform initializes the working directory described by opts, runs terraform graph and returns the parsed graph.
+func ParseTerraform(ctx context.Context, opts TerraformOptions) (*model.Graph, error) {
+	if err := validateGraphType(opts.GraphType); err != nil {
+		return nil, err
 	}
 
-	tf, err := tfexec.NewTerraform(workingDir, tfPath)
+	tf, err := newTerraform(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	if verbose {
-		utils.LogVerbose("Running terraform init with upgrade=true")
+	return terraformGraph(ctx, tf, opts)
+}
+
+// terraformGraph runs terraform graph with an initialized tf and returns the parsed graph.
+// When opts.Plan is set the graph is built from a speculative plan.
+func terraformGraph(ctx context.Context, tf *tfexec.Terraform, opts TerraformOptions) (*model.Graph, error) {
+	if opts.Plan {
+		planFile, cleanup, err := speculativePlan(ctx, tf, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		opts.PlanFile = planFile
 	}
 
-	if err := tf.Init(ctx, tfexec.Upgrade(true)); err != nil {
+	output, err := tf.Graph(ctx, graphOptions(opts)...)
+	if err != nil {
 		return nil, err
 	}
 
-	opts := &tfexec.GraphPlanOption{}
+	if opts.Verbose {
+		utils.LogVerbose("Successfully retrieved graph output from Terraform")
+	}
+
+	return parseGraph(output, opts.Verbose)
+}
 
-	if planFile != "" {
-		if verbose {
-			utils.LogVerbose("Using plan file for graph generation: %s", planFile)
+func validateGraphType(graphType string) error {
+	if graphType != "" && !validGraphTypes[graphType] {
+		return fmt.Errorf("%w %q: valid options are %s, %s, %s, %s", errInvalidGraphType, graphType, GraphTypePlan, GraphTypePlanDestroy, GraphTypePlanRefreshOnly, GraphTypeApply)
+	}
+	return nil
+}
+
+// graphOptions returns the terraform graph options for opts.
+func graphOptions(opts TerraformOptions) []tfexec.GraphOption {
+	var graphOpts []tfexec.GraphOption
+
+	if opts.PlanFile != "" {
+		if opts.Verbose {
+			utils.LogVerbose("Using plan file for graph generation: %s", opts.PlanFile)
 		}
-		opts = tfexec.GraphPlan(planFile)
-	} else if verbose {
+		graphOpts = append(graphOpts, tfexec.GraphPlan(opts.PlanFile))
+	} else if opts.Verbose {
 		utils.LogVerbose("No plan file specified, using current state")
 	}
 
-	if verbose {
+	if opts.GraphType != "" {
+		if opts.Verbose {
+			utils.LogVerbose("Using graph type: %s", opts.GraphType)
+		}
+		graphOpts = append(graphOpts, tfexec.GraphType(opts.GraphType))
+	}
+
+	if opts.DrawCycles {
+		if opts.Verbose {
+			utils.LogVerbose("Highlighting dependency cycles")
+		}
+		graphOpts = append(graphOpts, tfexec.DrawCycles(true))
+	}
+
+	if opts.Verbose {
 		utils.LogVerbose("Running terraform graph command")
 	}
 
-	output, err := tf.Graph(ctx, opts)
-	if err != nil {
+	return graphOpts
+}
+
+// ParseGraphFile reads a pre-generated `terraform graph` DOT file and returns the parsed graph.
+// A path of "-" reads the DOT graph from stdin. No Terraform binary is required.
+func ParseGraphFile(ctx context.Context, path string, verbose bool) (*model.Graph, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	if output == emptyGraph {
+	if verbose {
+		utils.LogVerbose("Reading DOT graph from %s", describeInput(path))
+	}
+
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errReadGraphFile, err)
+	}
+
+	return parseGraph(string(data), verbose)
+}
+
+// readInput reads the file at path, or stdin when path is "-".
+func readInput(path string) ([]byte, error) {
+	if path == stdinPath {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// describeInput returns a human-readable description of an input path for verbose logging.
+func describeInput(path string) string {
+	if path == stdinPath {
+		return "stdin"
+	}
+	return "file: " + path
+}
+
+// parseGraph parses DOT output, analyses it with gographviz and converts it with model.FromDOT. Output without any nodes, such as
+// the commented placeholder graph Terraform and OpenTofu print for configurations without resources, is
+// reported as errNoTerraformGraphData regardless of formatting differences between engines and versions.
+func parseGraph(output string, verbose bool) (*model.Graph, error) {
+	if strings.TrimSpace(output) == "" {
 		return nil, errNoTerraformGraphData
 	}
 
 	if verbose {
-		utils.LogVerbose("Successfully retrieved graph output from Terraform")
 		utils.LogVerbose("Parsing DOT output")
 	}
 
@@ -74,19 +151,25 @@ func ParseTerraform(ctx context.Context, workingDir, tfPath, planFile string, ve
 		return nil, err
 	}
 
-	graph := gographviz.NewGraph()
+	dot := gographviz.NewGraph()
 
 	if verbose {
 		utils.LogVerbose("Analyzing graph structure")
 	}
 
-	if err := gographviz.Analyse(graphAst, graph); err != nil {
+	if err := gographviz.Analyse(graphAst, dot); err != nil {
 		return nil, err
 	}
 
+	if len(dot.Nodes.Nodes) == 0 {
+		return nil, errNoTerraformGraphData
+	}
+
+	graph := model.FromDOT(dot)
+
 	if verbose {
 		utils.LogVerbose("Graph analysis complete")
-		utils.LogVerbose("Found %d nodes and %d edges", len(graph.Nodes.Nodes), len(graph.Edges.Edges))
+		utils.LogVerbose("Found %d nodes and %d edges", len(graph.Nodes), len(graph.Edges))
 	}
 
 	return graph, nil
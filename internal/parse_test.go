@@ -0,0 +1,90 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleGraph = `digraph G {
+  rankdir = "RL";
+  node [shape = rect, fontname = "sans-serif"];
+  "aws_subnet.private" [label="aws_subnet.private"];
+  "aws_vpc.main" [label="aws_vpc.main"];
+  "aws_subnet.private" -> "aws_vpc.main";
+}
+`
+
+const terraformEmptyGraph = `digraph G {
+  rankdir = "RL";
+  node [shape = rect, fontname = "sans-serif"];
+  /* This configuration does not contain any resources.         */
+  /* For a more detailed graph, try: terraform graph -type=plan */
+}
+`
+
+const tofuEmptyGraph = `digraph G {
+  rankdir = "RL";
+  node [shape = rect, fontname = "sans-serif"];
+  /* This configuration does not contain any resources.    */
+  /* For a more detailed graph, try: tofu graph -type=plan */
+}
+`
+
+func TestParseGraphFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "graph.dot")
+	if err := os.WriteFile(path, []byte(sampleGraph), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	graph, err := ParseGraphFile(context.Background(), path, false)
+	if err != nil {
+		t.Fatalf("ParseGraphFile() error = %v", err)
+	}
+	if got := len(graph.Nodes); got != 2 {
+		t.Errorf("ParseGraphFile() nodes = %d, want 2", got)
+	}
+	if got := len(graph.Edges); got != 1 {
+		t.Errorf("ParseGraphFile() edges = %d, want 1", got)
+	}
+}
+
+func TestParseGraphFile_Empty(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph string
+	}{
+		{name: "empty file", graph: ""},
+		{name: "terraform", graph: terraformEmptyGraph},
+		{name: "opentofu", graph: tofuEmptyGraph},
+		{name: "crlf line endings", graph: strings.ReplaceAll(terraformEmptyGraph, "\n", "\r\n")},
+		{name: "legacy empty digraph", graph: "digraph {\n\tcompound = \"true\"\n\tnewrank = \"true\"\n\tsubgraph \"root\" {\n\t}\n}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "graph.dot")
+			if err := os.WriteFile(path, []byte(tt.graph), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := ParseGraphFile(context.Background(), path, false); !errors.Is(err, errNoTerraformGraphData) {
+				t.Errorf("ParseGraphFile() error = %v, want %v", err, errNoTerraformGraphData)
+			}
+		})
+	}
+}
+
+func TestParseGraphFile_Missing(t *testing.T) {
+	_, err := ParseGraphFile(context.Background(), filepath.Join(t.TempDir(), "missing.dot"), false)
+	if !errors.Is(err, errReadGraphFile) {
+		t.Errorf("ParseGraphFile() error = %v, want %v", err, errReadGraphFile)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// ParsePlanJSONFile reads `terraform show -json` plan output from path, or stdin when path is "-",
+// and returns a graph whose nodes are tagged with their planned change action.
+func ParsePlanJSONFile(ctx context.Context, path string, verbose bool) (*model.Graph, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if verbose {
+		utils.LogVerbose("Reading plan JSON from %s", describeInput(path))
+	}
+
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errReadPlanFile, err)
+	}
+
+	var plan tfjson.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("%w: %w", errParsePlanJSON, err)
+	}
+
+	return BuildPlanGraph(&plan, verbose)
+}
+
+// ShowTerraformPlan renders opts.PlanFile, or a speculative plan when opts.Plan is set, with `terraform show -json`
+// and returns a graph whose nodes are tagged with their planned change action.
+func ShowTerraformPlan(ctx context.Context, opts TerraformOptions) (*model.Graph, error) {
+	tf, err := newTerraform(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Plan {
+		planFile, cleanup, err := speculativePlan(ctx, tf, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		opts.PlanFile = planFile
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("Running terraform show -json for plan file: %s", opts.PlanFile)
+	}
+
+	plan, err := tf.ShowPlanFile(ctx, opts.PlanFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildPlanGraph(plan, opts.Verbose)
+}
+
+// BuildPlanGraph builds a graph from a JSON plan with model.FromPlan. Nodes are created for every entry in
+// resource_changes and carry the planned action; edges follow the references and depends_on recorded in the plan's
+// configuration, including references that pass through module inputs and outputs.
+func BuildPlanGraph(plan *tfjson.Plan, verbose bool) (*model.Graph, error) {
+	if plan == nil || len(plan.ResourceChanges) == 0 {
+		return nil, errNoPlanResourceChanges
+	}
+
+	graph := model.FromPlan(plan)
+
+	if verbose {
+		utils.LogVerbose("Built plan graph with %d nodes and %d edges", len(graph.Nodes), len(graph.Edges))
+	}
+
+	return graph, nil
+}
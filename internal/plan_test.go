@@ -0,0 +1,51 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestParsePlanJSONFile(t *testing.T) {
+	graph, err := ParsePlanJSONFile(context.Background(), "testdata/plan.json", false)
+	if err != nil {
+		t.Fatalf("ParsePlanJSONFile() error = %v", err)
+	}
+
+	diagram, err := GenerateMermaidFlowchart(context.Background(), graph, "TD", "", false, nil, false)
+	if err != nil {
+		t.Fatalf("GenerateMermaidFlowchart() error = %v", err)
+	}
+
+	wantLines := []string{
+		`aws_subnet_private_0["aws_subnet.private[0]"]`,
+		`module_app_eu_aws_instance_web["module.app[eu].aws_instance.web"]`,
+		"aws_subnet_private_0 --> aws_vpc_main",
+		"aws_subnet_private_1 --> aws_vpc_main",
+		"module_app_eu_aws_instance_web --> aws_subnet_private_0",
+		"module_app_eu_aws_instance_web --> aws_subnet_private_1",
+		"aws_route53_record_web --> module_app_eu_aws_instance_web",
+		"class aws_subnet_private_0 create",
+		"class aws_subnet_private_1 update",
+		"class module_app_eu_aws_instance_web replace",
+		"class aws_route53_record_web delete",
+		"class aws_vpc_main noop",
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(diagram, want) {
+			t.Errorf("diagram missing %q\n%s", want, diagram)
+		}
+	}
+}
+
+func TestBuildPlanGraph_NoChanges(t *testing.T) {
+	if _, err := BuildPlanGraph(&tfjson.Plan{}, false); !errors.Is(err, errNoPlanResourceChanges) {
+		t.Errorf("BuildPlanGraph() error = %v, want %v", err, errNoPlanResourceChanges)
+	}
+}
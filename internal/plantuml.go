@@ -0,0 +1,172 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// FormatPlantUML is the output format of PlantUML component diagrams.
+const FormatPlantUML = "plantuml"
+
+var (
+	// plantumlAliasUnsafe matches the characters PlantUML does not accept in aliases.
+	plantumlAliasUnsafe = regexp.MustCompile(`\W+`)
+	// plantumlElements maps node kinds to the PlantUML element that draws them; other kinds are components.
+	plantumlElements = map[model.NodeKind]string{
+		model.KindProvider: "node",
+		model.KindModule:   "folder",
+		model.KindVariable: "interface",
+		model.KindLocal:    "interface",
+		model.KindOutput:   "interface",
+		model.KindMeta:     "rectangle",
+	}
+)
+
+func init() {
+	registerFormat(FormatPlantUML, ".puml", plantumlRenderer{})
+}
+
+// plantumlRenderer renders graphs as PlantUML component diagrams.
+type plantumlRenderer struct{}
+
+func (plantumlRenderer) Description() string {
+	return "PlantUML component diagram with packages for modules or providers"
+}
+
+// Render draws each node selected by selectFlowchart as a component inside nested packages for its modules, or a
+// package for its provider. PlantUML only lays out top to bottom or left to right, so BT is drawn like TB and RL
+// like LR.
+func (plantumlRenderer) Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error) {
+	if !validDirections[opts.Direction] {
+		return "", fmt.Errorf("%w %s: valid options are TB, TD, BT, RL, LR", errInvalidDirection, opts.Direction)
+	}
+	groupBy, err := validateGroupBy(opts.GroupBy)
+	if err != nil {
+		return "", err
+	}
+
+	state, filter, err := selectFlowchart(ctx, graph, opts)
+	if err != nil {
+		return "", err
+	}
+
+	root := &plantumlPackage{}
+	for _, n := range state.nodes {
+		path := containerPath(n.node, groupBy, filter)
+		pkg := root
+		if path != "" {
+			pkg = root.child(groupBy, path)
+		}
+		pkg.nodes = append(pkg.nodes, n)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("@startuml\n")
+	if opts.Title != "" {
+		fmt.Fprintf(&sb, "title %s\n", plantumlText(opts.Title))
+	}
+	if opts.Direction == "LR" || opts.Direction == "RL" {
+		sb.WriteString("left to right direction\n")
+	} else {
+		sb.WriteString("top to bottom direction\n")
+	}
+
+	indent := ""
+	if opts.SubgraphName != "" {
+		fmt.Fprintf(&sb, "package \"%s\" as %s {\n", plantumlText(opts.SubgraphName), plantumlAlias("group_"+CleanID(opts.SubgraphName)))
+		indent = "  "
+	}
+	root.write(&sb, indent)
+	if opts.SubgraphName != "" {
+		sb.WriteString("}\n")
+	}
+
+	for _, e := range state.edges {
+		arrow := "-->"
+		if e.color != "" {
+			arrow = "-[#" + e.color + "]->"
+		}
+		fmt.Fprintf(&sb, "%s %s %s\n", plantumlAlias(e.from), arrow, plantumlAlias(e.to))
+	}
+	sb.WriteString("@enduml\n")
+
+	if opts.Verbose {
+		utils.LogVerbose("PlantUML diagram generation complete with %d nodes and %d edges", len(state.nodes), len(state.edges))
+	}
+
+	return sb.String(), nil
+}
+
+// plantumlPackage is a package of a PlantUML diagram: a module, or a provider when grouping by provider.
+type plantumlPackage struct {
+	alias    string
+	name     string
+	nodes    []selectedNode
+	children map[string]*plantumlPackage
+}
+
+// child returns the package for the dot-joined module path below p, creating it and its parents as needed.
+// Provider names are never split.
+func (p *plantumlPackage) child(groupBy, path string) *plantumlPackage {
+	names := []string{path}
+	if groupBy == GroupByModule {
+		names = strings.Split(path, ".")
+	}
+
+	pkg := p
+	for i, name := range names {
+		if pkg.children == nil {
+			pkg.children = make(map[string]*plantumlPackage)
+		}
+		next, ok := pkg.children[name]
+		if !ok {
+			next = &plantumlPackage{alias: plantumlAlias("pkg_" + groupBy + "_" + CleanID(strings.Join(names[:i+1], "."))), name: name}
+			pkg.children[name] = next
+		}
+		pkg = next
+	}
+	return pkg
+}
+
+// write writes the nodes of p followed by its child packages, sorted by name.
+func (p *plantumlPackage) write(sb *strings.Builder, indent string) {
+	for _, n := range p.nodes {
+		element, ok := plantumlElements[n.node.Kind]
+		if !ok {
+			element = "component"
+		}
+		fmt.Fprintf(sb, "%s%s \"%s\" as %s", indent, element, plantumlText(n.label), plantumlAlias(n.id))
+		if n.node.Action != "" {
+			fmt.Fprintf(sb, " <<%s>>", n.node.Action)
+		}
+		sb.WriteString("\n")
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.children)) {
+		child := p.children[name]
+		fmt.Fprintf(sb, "%spackage \"%s\" as %s {\n", indent, plantumlText(child.name), child.alias)
+		child.write(sb, indent+"  ")
+		fmt.Fprintf(sb, "%s}\n", indent)
+	}
+}
+
+// plantumlAlias returns a cleaned ID (see CleanID) with the characters PlantUML does not accept in aliases
+// replaced by underscores.
+func plantumlAlias(id string) string {
+	return plantumlAliasUnsafe.ReplaceAllString(id, "_")
+}
+
+// plantumlText returns s as it can appear between double quotes in PlantUML: double quotes become single quotes
+// and line breaks become spaces.
+func plantumlText(s string) string {
+	return strings.NewReplacer(`"`, "'", "\r\n", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,72 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPlantUMLRenderer(t *testing.T) {
+	graph, err := ParseStatic(context.Background(), "testdata/static", false)
+	if err != nil {
+		t.Fatalf("ParseStatic() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		opts  RenderOptions
+		wants []string
+		avoid []string
+	}{
+		{
+			name: "module packages",
+			opts: RenderOptions{Direction: "TD", SubgraphName: "Terraform", Title: `Workspace: "dev"`},
+			wants: []string{
+				"@startuml\ntitle Workspace: 'dev'\ntop to bottom direction\npackage \"Terraform\" as group_Terraform {\n",
+				"  component \"aws_vpc.main\" as aws_vpc_main\n",
+				"  interface \"var.cidr_block\" as var_cidr_block\n",
+				"  folder \"module.app\" as module_app\n",
+				"  package \"app\" as pkg_module_app {\n    interface \"module.app.var.subnet_id\" as module_app_var_subnet_id\n",
+				"aws_subnet_private --> aws_vpc_main\n",
+				"  }\n}\naws_subnet_private --> aws_vpc_main\n",
+				"output_web_ip --> module_app_output_public_ip\n@enduml\n",
+			},
+		},
+		{
+			name: "provider packages",
+			opts: RenderOptions{Direction: "RL", ResourcesOnly: true, GroupBy: GroupByProvider},
+			wants: []string{
+				"left to right direction\npackage \"aws\" as pkg_provider_aws {\n",
+				"  component \"module.app.aws_instance.web\" as module_app_aws_instance_web\n",
+			},
+			avoid: []string{"var_cidr_block", "group_", "pkg_module"},
+		},
+		{
+			name:  "module calls",
+			opts:  RenderOptions{Direction: "TD"},
+			avoid: []string{"pkg_module_remote"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagram, err := plantumlRenderer{}.Render(context.Background(), graph, tt.opts)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(diagram, want) {
+					t.Errorf("Render() missing %q:\n%s", want, diagram)
+				}
+			}
+			for _, avoid := range tt.avoid {
+				if strings.Contains(diagram, avoid) {
+					t.Errorf("Render() contains %q:\n%s", avoid, diagram)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Defaults used to expand short provider source addresses such as "aws" and "hashicorp/aws".
+const (
+	defaultProviderRegistry  = "registry.terraform.io"
+	defaultProviderNamespace = "hashicorp"
+)
+
+// lockFileName is the dependency lock file written by terraform init.
+const lockFileName = ".terraform.lock.hcl"
+
+var (
+	lockFileSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "provider", LabelNames: []string{"source"}}},
+	}
+	requiredProvidersSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "terraform"}},
+	}
+	terraformRequiredProvidersSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "required_providers"}},
+	}
+)
+
+// ProviderRef identifies the provider configuration that manages a resource.
+type ProviderRef struct {
+	Source    string // Fully qualified source address, e.g. registry.terraform.io/hashicorp/aws; empty if unknown
+	LocalName string // Name the configuration uses for the provider, e.g. google-beta
+	Alias     string // Provider configuration alias, e.g. west; empty for the default configuration
+}
+
+// Type returns the provider type, i.e. the last segment of its source address, or the local name when the
+// source is unknown.
+func (p ProviderRef) Type() string {
+	if p.Source == "" {
+		return p.LocalName
+	}
+	return p.Source[strings.LastIndex(p.Source, "/")+1:]
+}
+
+// Matches reports whether the provider is selected by pattern. Patterns containing a slash are source
+// addresses matched in full or by suffix ("hashicorp/aws"). Other patterns are a provider name ("aws"). Either
+// form takes an optional alias ("aws.west", "hashicorp/aws.west"); a pattern without an alias matches every
+// configuration of that provider.
+func (p ProviderRef) Matches(pattern string) bool {
+	// Provider types never contain dots, so a dot after the last slash separates the alias.
+	slash := strings.LastIndex(pattern, "/")
+	name, alias, aliased := strings.Cut(pattern[slash+1:], ".")
+	if aliased && !strings.EqualFold(alias, p.Alias) {
+		return false
+	}
+
+	if slash >= 0 {
+		source := strings.ToLower(p.Source)
+		pattern = strings.ToLower(pattern[:slash+1] + name)
+		return source != "" && (source == pattern || strings.HasSuffix(source, "/"+pattern))
+	}
+	return strings.EqualFold(name, p.LocalName) || strings.EqualFold(name, p.Type())
+}
+
+// String returns the provider in a form its Matches accepts, e.g. "registry.terraform.io/hashicorp/aws.west".
+func (p ProviderRef) String() string {
+	name := p.Source
+	if name == "" {
+		name = p.LocalName
+	}
+	if p.Alias != "" {
+		name += "." + p.Alias
+	}
+	return name
+}
+
+// ResolveProviders returns the provider of each resource in graph, keyed by the resource's cleaned label.
+// Providers are taken from the edges between resources and `provider["..."]` nodes that terraform graph emits.
+// Resources without such an edge fall back to the provider named by their type prefix, whose source is looked
+// up in the required_providers blocks and then in the dependency lock file of workingDir. Resources whose
+// provider cannot be resolved are omitted.
+func ResolveProviders(graph *model.Graph, workingDir string, verbose bool) (map[string]ProviderRef, error) {
+	refs := make(map[string]ProviderRef)
+	for _, node := range graph.Nodes {
+		if node.Provider != "" && isResourceNode(node) {
+			refs[node.Label] = nodeProviderRef(node)
+		}
+	}
+
+	sources, err := configuredProviderSources(workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range graph.Nodes {
+		if _, resolved := refs[node.Label]; resolved || !isResourceNode(node) {
+			continue
+		}
+		if source, ok := sources[node.Address.Provider()]; ok {
+			refs[node.Label] = ProviderRef{Source: source, LocalName: node.Address.Provider()}
+		}
+	}
+
+	if verbose {
+		utils.LogVerbose("Resolved providers for %d resources", len(refs))
+	}
+
+	return refs, nil
+}
+
+// nodeProviderRef returns the provider recorded on n, e.g. from its edge to a provider configuration node.
+func nodeProviderRef(n *model.Node) ProviderRef {
+	ref := ProviderRef{Source: n.Provider, Alias: n.ProviderAlias}
+	ref.LocalName = ref.Type()
+	return ref
+}
+
+// isResourceNode reports whether n is a managed resource or data source.
+func isResourceNode(n *model.Node) bool {
+	return n.Kind == model.KindResource || n.Kind == model.KindData
+}
+
+// configuredProviderSources maps provider local names to source addresses using the required_providers blocks
+// in the .tf and .tf.json files of dir, then the providers recorded in its dependency lock file. Missing files
+// are not an error.
+func configuredProviderSources(dir string) (map[string]string, error) {
+	sources := make(map[string]string)
+	parser := hclparse.NewParser()
+
+	lockPath := filepath.Join(dir, lockFileName)
+	if _, err := os.Stat(lockPath); err == nil {
+		file, diags := parser.ParseHCLFile(lockPath)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("%w: %w", errParseHCL, diags)
+		}
+		content, _, _ := file.Body.PartialContent(lockFileSchema)
+		for _, block := range content.Blocks {
+			source := normalizeProviderSource(block.Labels[0])
+			sources[source[strings.LastIndex(source, "/")+1:]] = source
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sources, nil
+		}
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		var file *hcl.File
+		var diags hcl.Diagnostics
+		switch path := filepath.Join(dir, entry.Name()); {
+		case strings.HasSuffix(path, ".tf"):
+			file, diags = parser.ParseHCLFile(path)
+		case strings.HasSuffix(path, ".tf.json"):
+			file, diags = parser.ParseJSONFile(path)
+		default:
+			continue
+		}
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("%w: %w", errParseHCL, diags)
+		}
+		// required_providers takes precedence over the lock file because it carries the local names.
+		for name, source := range requiredProviders(file.Body) {
+			sources[name] = source
+		}
+	}
+
+	return sources, nil
+}
+
+// requiredProviders returns the local name to source mapping declared in the required_providers blocks of body.
+func requiredProviders(body hcl.Body) map[string]string {
+	out := make(map[string]string)
+	content, _, _ := body.PartialContent(requiredProvidersSchema)
+	for _, tfBlock := range content.Blocks {
+		tfContent, _, _ := tfBlock.Body.PartialContent(terraformRequiredProvidersSchema)
+		for _, block := range tfContent.Blocks {
+			attrs, _ := block.Body.JustAttributes()
+			for name, attr := range attrs {
+				source := name
+				if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.Type().IsObjectType() && value.Type().HasAttribute("source") {
+					if s := value.GetAttr("source"); s.Type().Equals(cty.String) && s.IsKnown() && !s.IsNull() {
+						source = s.AsString()
+					}
+				}
+				out[name] = normalizeProviderSource(source)
+			}
+		}
+	}
+	return out
+}
+
+// normalizeProviderSource expands a short provider source address to its fully qualified form.
+func normalizeProviderSource(source string) string {
+	switch strings.Count(source, "/") {
+	case 0:
+		return defaultProviderRegistry + "/" + defaultProviderNamespace + "/" + source
+	case 1:
+		return defaultProviderRegistry + "/" + source
+	default:
+		return source
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// providerGraph is terraform graph output in which resources point at their provider configurations.
+const providerGraph = `digraph G {
+  "[root] google_compute_instance.beta (expand)" [label = "google_compute_instance.beta"];
+  "[root] aws_instance.east (expand)" [label = "aws_instance.east"];
+  "[root] aws_instance.west (expand)" [label = "aws_instance.west"];
+  "[root] mycloud_thing.x (expand)" [label = "mycloud_thing.x"];
+  "[root] random_pet.name (expand)" [label = "random_pet.name"];
+  "[root] othercloud_thing.y (expand)" [label = "othercloud_thing.y"];
+  "[root] provider[\"registry.terraform.io/hashicorp/google-beta\"]" [label = "provider[\"registry.terraform.io/hashicorp/google-beta\"]"];
+  "[root] provider[\"registry.terraform.io/hashicorp/aws\"]" [label = "provider[\"registry.terraform.io/hashicorp/aws\"]"];
+  "[root] provider[\"registry.terraform.io/hashicorp/aws\"].west" [label = "provider[\"registry.terraform.io/hashicorp/aws\"].west"];
+  "[root] google_compute_instance.beta (expand)" -> "[root] provider[\"registry.terraform.io/hashicorp/google-beta\"]";
+  "[root] aws_instance.east (expand)" -> "[root] provider[\"registry.terraform.io/hashicorp/aws\"]";
+  "[root] aws_instance.west (expand)" -> "[root] provider[\"registry.terraform.io/hashicorp/aws\"].west";
+}
+`
+
+func TestResolveProviders(t *testing.T) {
+	graph, err := parseGraph(providerGraph, false)
+	if err != nil {
+		t.Fatalf("parseGraph() error = %v", err)
+	}
+
+	refs, err := ResolveProviders(graph, filepath.Join("testdata", "providers"), false)
+	if err != nil {
+		t.Fatalf("ResolveProviders() error = %v", err)
+	}
+
+	want := map[string]ProviderRef{
+		"google_compute_instance.beta": {Source: "registry.terraform.io/hashicorp/google-beta", LocalName: "google-beta"},
+		"aws_instance.east":            {Source: "registry.terraform.io/hashicorp/aws", LocalName: "aws"},
+		"aws_instance.west":            {Source: "registry.terraform.io/hashicorp/aws", LocalName: "aws", Alias: "west"},
+		"mycloud_thing.x":              {Source: "registry.terraform.io/acme/cloud", LocalName: "mycloud"},
+		"random_pet.name":              {Source: "registry.terraform.io/hashicorp/random", LocalName: "random"},
+		"othercloud_thing.y":           {Source: "registry.terraform.io/acme/othercloud", LocalName: "othercloud"},
+	}
+	if len(refs) != len(want) {
+		t.Errorf("ResolveProviders() resolved %d resources, want %d: %v", len(refs), len(want), refs)
+	}
+	for label, ref := range want {
+		if refs[label] != ref {
+			t.Errorf("ResolveProviders()[%s] = %+v, want %+v", label, refs[label], ref)
+		}
+	}
+}
+
+func TestProviderRefMatches(t *testing.T) {
+	west := ProviderRef{Source: "registry.terraform.io/hashicorp/aws", LocalName: "aws", Alias: "west"}
+	beta := ProviderRef{Source: "registry.terraform.io/hashicorp/google-beta", LocalName: "google-beta"}
+	tests := []struct {
+		name    string
+		ref     ProviderRef
+		pattern string
+		want    bool
+	}{
+		{name: "type", ref: west, pattern: "aws", want: true},
+		{name: "alias", ref: west, pattern: "aws.west", want: true},
+		{name: "other alias", ref: west, pattern: "aws.east", want: false},
+		{name: "namespace and type", ref: west, pattern: "hashicorp/aws", want: true},
+		{name: "full source", ref: west, pattern: "registry.terraform.io/hashicorp/aws", want: true},
+		{name: "source and alias", ref: west, pattern: "hashicorp/aws.west", want: true},
+		{name: "full source and alias", ref: west, pattern: "registry.terraform.io/hashicorp/aws.west", want: true},
+		{name: "source and other alias", ref: west, pattern: "hashicorp/aws.east", want: false},
+		{name: "case insensitive", ref: west, pattern: "HashiCorp/AWS", want: true},
+		{name: "partial type does not match", ref: west, pattern: "corp/aws", want: false},
+		{name: "beta is not google", ref: beta, pattern: "google", want: false},
+		{name: "beta by name", ref: beta, pattern: "google-beta", want: true},
+		{name: "unresolved local name", ref: ProviderRef{LocalName: "aws"}, pattern: "aws", want: true},
+		{name: "unresolved source", ref: ProviderRef{LocalName: "aws"}, pattern: "hashicorp/aws", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ref.Matches(tt.pattern); got != tt.want {
+				t.Errorf("%+v.Matches(%q) = %v, want %v", tt.ref, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderRefMatches_String(t *testing.T) {
+	tests := []ProviderRef{
+		{Source: "registry.terraform.io/hashicorp/aws", LocalName: "aws"},
+		{Source: "registry.terraform.io/hashicorp/aws", LocalName: "aws", Alias: "west"},
+		{Source: "example.com/acme/cloud", LocalName: "mycloud", Alias: "eu"},
+		{LocalName: "aws"},
+		{LocalName: "aws", Alias: "west"},
+	}
+
+	for _, p := range tests {
+		t.Run(p.String(), func(t *testing.T) {
+			if !p.Matches(p.String()) {
+				t.Errorf("%+v.Matches(%q) = false, want true", p, p.String())
+			}
+		})
+	}
+}
+
+func TestGenerateMermaidFlowchart_ResolvedProviders(t *testing.T) {
+	graph, err := parseGraph(providerGraph, false)
+	if err != nil {
+		t.Fatalf("parseGraph() error = %v", err)
+	}
+	refs, err := ResolveProviders(graph, filepath.Join("testdata", "providers"), false)
+	if err != nil {
+		t.Fatalf("ResolveProviders() error = %v", err)
+	}
+
+	filter := &FilterConfig{IncludeProviders: []string{"hashicorp/google-beta", "aws.west"}, Providers: refs}
+	diagram, err := GenerateMermaidFlowchart(context.Background(), graph, "TD", "", true, filter, false)
+	if err != nil {
+		t.Fatalf("GenerateMermaidFlowchart() error = %v", err)
+	}
+
+	for _, label := range []string{"google_compute_instance.beta", "aws_instance.west"} {
+		if !strings.Contains(diagram, `["`+label+`"]`) {
+			t.Errorf("diagram is missing %s:\n%s", label, diagram)
+		}
+	}
+	for _, label := range []string{"aws_instance.east", "mycloud_thing.x", "random_pet.name", "othercloud_thing.y"} {
+		if strings.Contains(diagram, `["`+label+`"]`) {
+			t.Errorf("diagram unexpectedly contains %s:\n%s", label, diagram)
+		}
+	}
+}
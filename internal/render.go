@@ -0,0 +1,157 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+)
+
+// ChartTypeFlowchart is the default Mermaid chart type.
+const ChartTypeFlowchart = "flowchart"
+
+// FormatMermaid is the default output format. Its diagram is chosen by the chart type; other formats draw the
+// nodes and edges of a flowchart in another diagram language.
+const FormatMermaid = "mermaid"
+
+// Renderer renders a graph as one kind of diagram.
+type Renderer interface {
+	// Render returns the diagram source for graph.
+	Render(ctx context.Context, graph *model.Graph, opts RenderOptions) (string, error)
+	// Description summarizes the diagram in one line, e.g. for terramaid run --list-chart-types.
+	Description() string
+}
+
+// RenderOptions configures a Renderer.
+type RenderOptions struct {
+	Direction     string            // Layout direction: TB, TD, BT, RL or LR
+	SubgraphName  string            // Name of the subgraph wrapping the diagram; empty for none
+	ResourcesOnly bool              // Only include resource nodes and the edges between them
+	Filter        *FilterConfig     // Resource filters; nil includes every node
+	GroupBy       string            // Grouping of architecture services and of other formats' nodes: module (default) or provider
+	IconMap       map[string]string // Architecture icons keyed by resource type or glob pattern; overrides the defaults
+	// IncludeAttributes and ExcludeAttributes select the attributes shown in class diagrams by name or glob
+	// pattern. IncludeAttributes defaults to common attributes such as instance_type and cidr_block.
+	IncludeAttributes []string
+	ExcludeAttributes []string
+	Title             string    // Diagram title for formats other than Mermaid, which get theirs from AddMermaidTitle
+	GeneratedAt       time.Time // Generation time recorded by formats with metadata; defaults to the current time
+	Verbose           bool
+}
+
+// renderers maps chart types to their renderer, and formats maps output formats other than Mermaid to theirs. It is only written by the init functions of the files that
+// define renderers, so it is safe for concurrent use afterwards.
+var (
+	renderers = make(map[string]Renderer)
+	formats   = make(map[string]Renderer)
+	// formatExtensions maps output formats to the file extension of their output.
+	formatExtensions = map[string]string{FormatMermaid: ".md"}
+)
+
+// registerRenderer makes r available as chartType. It must only be called from init functions.
+func registerRenderer(chartType string, r Renderer) {
+	if _, exists := renderers[chartType]; exists {
+		panic("renderer already registered for chart type " + chartType)
+	}
+	renderers[chartType] = r
+}
+
+// ChartTypes returns the registered chart types, sorted.
+func ChartTypes() []string {
+	types := make([]string, 0, len(renderers))
+	for chartType := range renderers {
+		types = append(types, chartType)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// LookupRenderer returns the renderer registered for chartType.
+func LookupRenderer(chartType string) (Renderer, error) {
+	r, ok := renderers[chartType]
+	if !ok {
+		return nil, fmt.Errorf("%w %q: valid options are %s (see terramaid run --list-chart-types)", errUnknownChartType, chartType, strings.Join(ChartTypes(), ", "))
+	}
+	return r, nil
+}
+
+// registerFormat makes r available as format, whose output files have extension. It must only be called from
+// init functions.
+func registerFormat(format, extension string, r Renderer) {
+	if _, exists := formats[format]; exists || format == FormatMermaid {
+		panic("renderer already registered for format " + format)
+	}
+	formats[format] = r
+	formatExtensions[format] = extension
+}
+
+// Formats returns the supported output formats, sorted.
+func Formats() []string {
+	names := make([]string, 0, len(formats)+1)
+	names = append(names, FormatMermaid)
+	for format := range formats {
+		names = append(names, format)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// FormatExtension returns the file extension, including the dot, of output in format, or "" for unknown formats.
+func FormatExtension(format string) string {
+	return formatExtensions[format]
+}
+
+// LookupFormat returns the renderer for format, or for chartType when format is Mermaid. Other formats only draw
+// flowcharts.
+func LookupFormat(format, chartType string) (Renderer, error) {
+	if format == FormatMermaid {
+		return LookupRenderer(chartType)
+	}
+	r, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("%w %q: valid options are %s", errUnknownFormat, format, strings.Join(Formats(), ", "))
+	}
+	if chartType != ChartTypeFlowchart {
+		return nil, fmt.Errorf("%w: --chart-type %s requires --format %s", errFormatChartType, chartType, FormatMermaid)
+	}
+	return r, nil
+}
+
+// validateGroupBy returns groupBy, defaulting to GroupByModule, or an error if it is not a supported grouping.
+func validateGroupBy(groupBy string) (string, error) {
+	switch groupBy {
+	case "":
+		return GroupByModule, nil
+	case GroupByModule, GroupByProvider:
+		return groupBy, nil
+	default:
+		return "", fmt.Errorf("%w %q: valid options are %s, %s", errInvalidGroupBy, groupBy, GroupByModule, GroupByProvider)
+	}
+}
+
+// generationTime returns o.GeneratedAt, or the current time when it is not set.
+func (o RenderOptions) generationTime() time.Time {
+	if o.GeneratedAt.IsZero() {
+		return time.Now()
+	}
+	return o.GeneratedAt
+}
+
+// containerPath returns the dot-joined module path of the package, container or cluster a format draws n in, or
+// the provider of n when grouping by provider. Module calls are drawn in the module that calls them.
+func containerPath(n *model.Node, groupBy string, filter *FilterConfig) string {
+	if groupBy == GroupByProvider {
+		return nodeProvider(n, filter)
+	}
+	path := n.ModulePath()
+	if n.Kind == model.KindModule {
+		path = path[:max(strings.LastIndex(path, "."), 0)]
+	}
+	return path
+}
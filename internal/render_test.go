@@ -0,0 +1,74 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLookupRenderer(t *testing.T) {
+	if !slices.Contains(ChartTypes(), ChartTypeFlowchart) {
+		t.Fatalf("ChartTypes() = %v, want %s registered", ChartTypes(), ChartTypeFlowchart)
+	}
+
+	r, err := LookupRenderer(ChartTypeFlowchart)
+	if err != nil {
+		t.Fatalf("LookupRenderer(%q) error = %v", ChartTypeFlowchart, err)
+	}
+	graph, err := parseGraph(classifyGraph, false)
+	if err != nil {
+		t.Fatalf("parseGraph() error = %v", err)
+	}
+	diagram, err := r.Render(context.Background(), graph, RenderOptions{Direction: "LR"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.HasPrefix(diagram, "```mermaid\nflowchart LR\n") {
+		t.Errorf("Render() =\n%s", diagram)
+	}
+
+	_, err = LookupRenderer("pie")
+	if !errors.Is(err, errUnknownChartType) || !strings.Contains(err.Error(), ChartTypeFlowchart) {
+		t.Errorf("LookupRenderer(%q) error = %v, want %v listing the chart types", "pie", err, errUnknownChartType)
+	}
+}
+
+func TestLookupFormat(t *testing.T) {
+	tests := []struct {
+		format    string
+		chartType string
+		want      Renderer
+		wantErr   error
+	}{
+		{format: FormatMermaid, chartType: ChartTypeFlowchart, want: flowchartRenderer{}},
+		{format: FormatMermaid, chartType: ChartTypeClass, want: classRenderer{}},
+		{format: FormatPlantUML, chartType: ChartTypeFlowchart, want: plantumlRenderer{}},
+		{format: FormatPlantUML, chartType: ChartTypeClass, wantErr: errFormatChartType},
+		{format: FormatD2, chartType: ChartTypeFlowchart, want: d2Renderer{}},
+		{format: FormatDOT, chartType: ChartTypeFlowchart, want: dotRenderer{}},
+		{format: FormatJSON, chartType: ChartTypeFlowchart, want: jsonRenderer{}},
+		{format: FormatGraphML, chartType: ChartTypeFlowchart, want: graphmlRenderer{}},
+		{format: FormatGEXF, chartType: ChartTypeFlowchart, want: gexfRenderer{}},
+		{format: "svg", chartType: ChartTypeFlowchart, wantErr: errUnknownFormat},
+	}
+
+	for _, tt := range tests {
+		got, err := LookupFormat(tt.format, tt.chartType)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("LookupFormat(%q, %q) = %T, %v, want %T, %v", tt.format, tt.chartType, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if formats := Formats(); !slices.Contains(formats, FormatMermaid) || !slices.Contains(formats, FormatPlantUML) {
+		t.Errorf("Formats() = %v", formats)
+	}
+	for _, format := range Formats() {
+		if FormatExtension(format) == "" {
+			t.Errorf("FormatExtension(%q) is empty", format)
+		}
+	}
+}
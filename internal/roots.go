@@ -0,0 +1,170 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// rootModuleSchema describes the top-level blocks that identify a root module or its local child modules.
+var rootModuleSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+	},
+}
+
+// terraformBlockSchema describes the terraform block settings that configure state storage.
+var terraformBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "backend", LabelNames: []string{"type"}},
+		{Type: "cloud"},
+	},
+}
+
+// DiscoverRootModules walks root and returns the directories that are Terraform root modules: directories whose
+// configuration declares a backend, a cloud block or a provider, and that no other directory uses as a local
+// module source. Directories whose configuration cannot be parsed are also returned so that the failure is
+// reported for that directory. Paths are relative to root and sorted; root itself is returned as ".".
+func DiscoverRootModules(ctx context.Context, root string, verbose bool) ([]string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	candidates := make(map[string]bool)
+	children := make(map[string]bool)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if discoverySkipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+
+		isRoot, calls, err := scanModuleDir(parser, path)
+		if errors.Is(err, errParseHCL) {
+			// Report invalid configuration as a failed root rather than aborting discovery of the others.
+			if verbose {
+				utils.LogVerbose("Treating %s as a root module: %v", path, err)
+			}
+			isRoot = true
+		} else if err != nil {
+			return err
+		}
+		if isRoot {
+			candidates[path] = true
+		}
+		for _, dir := range calls {
+			children[dir] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []string
+	for dir := range candidates {
+		if children[dir] {
+			if verbose {
+				utils.LogVerbose("Skipping %s: used as a child module", dir)
+			}
+			continue
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, rel)
+	}
+
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%w under %s", errNoRootModules, root)
+	}
+
+	sort.Strings(roots)
+	if verbose {
+		utils.LogVerbose("Discovered %d root modules under %s", len(roots), root)
+	}
+
+	return roots, nil
+}
+
+// scanModuleDir parses the Terraform files in dir and reports whether it configures a backend, cloud block or
+// provider, along with the cleaned directories of its local module calls.
+func scanModuleDir(parser *hclparse.Parser, dir string) (bool, []string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var (
+		isRoot bool
+		calls  []string
+	)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		var (
+			file  *hcl.File
+			diags hcl.Diagnostics
+		)
+		switch {
+		case strings.HasSuffix(entry.Name(), ".tf.json"):
+			file, diags = parser.ParseJSONFile(path)
+		case strings.HasSuffix(entry.Name(), ".tf"):
+			file, diags = parser.ParseHCLFile(path)
+		default:
+			continue
+		}
+		if diags.HasErrors() {
+			return false, nil, fmt.Errorf("%w: %w", errParseHCL, diags)
+		}
+
+		content, _, diags := file.Body.PartialContent(rootModuleSchema)
+		if diags.HasErrors() {
+			return false, nil, fmt.Errorf("%w: %w", errParseHCL, diags)
+		}
+
+		if len(content.Blocks.OfType("provider")) > 0 {
+			isRoot = true
+		}
+		for _, block := range content.Blocks.OfType("terraform") {
+			settings, _, _ := block.Body.PartialContent(terraformBlockSchema)
+			if len(settings.Blocks) > 0 {
+				isRoot = true
+			}
+		}
+		for _, block := range content.Blocks.OfType("module") {
+			if source := moduleSource(block); isLocalModuleSource(source) {
+				calls = append(calls, filepath.Join(dir, source))
+			}
+		}
+	}
+
+	return isRoot, calls, nil
+}
@@ -0,0 +1,31 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiscoverRootModules(t *testing.T) {
+	roots, err := DiscoverRootModules(context.Background(), filepath.Join("testdata", "roots"), false)
+	if err != nil {
+		t.Fatalf("DiscoverRootModules() error = %v", err)
+	}
+
+	want := []string{filepath.Join("live", "dev"), filepath.Join("live", "prod")}
+	if !slices.Equal(roots, want) {
+		t.Errorf("DiscoverRootModules() = %v, want %v", roots, want)
+	}
+}
+
+func TestDiscoverRootModules_None(t *testing.T) {
+	_, err := DiscoverRootModules(context.Background(), filepath.Join("testdata", "roots", "modules", "unused"), false)
+	if !errors.Is(err, errNoRootModules) {
+		t.Errorf("DiscoverRootModules() error = %v, want %v", err, errNoRootModules)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// supportedStateVersion is the Terraform state file format version understood by BuildStateGraph.
+const supportedStateVersion = 4
+
+// ParseStateFile reads a Terraform v4 state file from path, or stdin when path is "-", and returns a graph
+// of the deployed resource instances.
+func ParseStateFile(ctx context.Context, path string, verbose bool) (*model.Graph, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if verbose {
+		utils.LogVerbose("Reading Terraform state from %s", describeInput(path))
+	}
+
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errReadStateFile, err)
+	}
+
+	return BuildStateGraph(data, verbose)
+}
+
+// PullTerraformState runs `terraform state pull` in opts.WorkingDir and returns a graph of the deployed resource instances.
+func PullTerraformState(ctx context.Context, opts TerraformOptions) (*model.Graph, error) {
+	tf, err := newTerraform(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("Running terraform state pull")
+	}
+
+	state, err := tf.StatePull(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildStateGraph([]byte(state), opts.Verbose)
+}
+
+// BuildStateGraph builds a graph from Terraform v4 state JSON. Every managed and data resource instance becomes
+// a node, and the dependencies recorded on each instance become edges to every instance of the dependency.
+func BuildStateGraph(data []byte, verbose bool) (*model.Graph, error) {
+	var state model.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%w: %w", errParseState, err)
+	}
+	if state.Version != supportedStateVersion {
+		return nil, fmt.Errorf("%w: %d", errUnsupportedStateVersion, state.Version)
+	}
+
+	graph := model.FromState(&state)
+	if len(graph.Nodes) == 0 {
+		return nil, errNoStateResources
+	}
+
+	if verbose {
+		utils.LogVerbose("Built state graph with %d nodes and %d edges", len(graph.Nodes), len(graph.Edges))
+	}
+
+	return graph, nil
+}
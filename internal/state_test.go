@@ -0,0 +1,47 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseStateFile(t *testing.T) {
+	graph, err := ParseStateFile(context.Background(), "testdata/terraform.tfstate", false)
+	if err != nil {
+		t.Fatalf("ParseStateFile() error = %v", err)
+	}
+
+	filter := &FilterConfig{ExcludeTypes: []string{"aws_ami"}}
+	diagram, err := GenerateMermaidFlowchart(context.Background(), graph, "TD", "", false, filter, false)
+	if err != nil {
+		t.Fatalf("GenerateMermaidFlowchart() error = %v", err)
+	}
+
+	wantLines := []string{
+		`module_app_eu_aws_instance_web["module.app[eu].aws_instance.web"]`,
+		"aws_subnet_private_0 --> aws_vpc_main",
+		"aws_subnet_private_1 --> aws_vpc_main",
+		"module_app_eu_aws_instance_web --> aws_subnet_private_0",
+		"module_app_eu_aws_instance_web --> aws_subnet_private_1",
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(diagram, want) {
+			t.Errorf("diagram missing %q\n%s", want, diagram)
+		}
+	}
+	if strings.Contains(diagram, "data_aws_ami_ubuntu") {
+		t.Errorf("diagram contains filtered data source\n%s", diagram)
+	}
+}
+
+func TestBuildStateGraph_UnsupportedVersion(t *testing.T) {
+	_, err := BuildStateGraph([]byte(`{"version": 3, "resources": []}`), false)
+	if !errors.Is(err, errUnsupportedStateVersion) {
+		t.Errorf("BuildStateGraph() error = %v, want %v", err, errUnsupportedStateVersion)
+	}
+}
@@ -0,0 +1,361 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// maxModuleDepth bounds how deeply local module sources are followed.
+const maxModuleDepth = 32
+
+// staticRootSchema describes the top-level Terraform blocks that take part in the dependency graph.
+var staticRootSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "locals"},
+	},
+}
+
+// moduleMetaArguments are module block arguments that are not input variables of the called module.
+var moduleMetaArguments = map[string]bool{
+	"source": true, "version": true, "providers": true, "count": true, "for_each": true, "depends_on": true,
+}
+
+// ParseStatic parses the .tf and .tf.json files in workingDir with an HCL parser and returns the dependency
+// graph between resources, data sources, modules, variables, locals and outputs. Local module sources are
+// followed. No Terraform binary, init or credentials are required.
+func ParseStatic(ctx context.Context, workingDir string, verbose bool) (*model.Graph, error) {
+	b := model.NewBuilder()
+	p := &staticParser{parser: hclparse.NewParser(), builder: b, verbose: verbose}
+	if err := p.parseModule(ctx, "", workingDir, nil, 0); err != nil {
+		return nil, err
+	}
+
+	for _, edge := range p.edges {
+		b.AddReference(edge.from, edge.to, edge.ref)
+	}
+
+	graph := b.Graph()
+	if len(graph.Nodes) == 0 {
+		return nil, errNoTerraformGraphData
+	}
+
+	if verbose {
+		utils.LogVerbose("Built static graph with %d nodes and %d edges", len(graph.Nodes), len(graph.Edges))
+	}
+
+	return graph, nil
+}
+
+type staticParser struct {
+	parser  *hclparse.Parser
+	builder *model.Builder
+	edges   []staticEdge
+	verbose bool
+}
+
+// staticEdge is a dependency found in the configuration and the reference that causes it.
+type staticEdge struct {
+	from, to string
+	ref      model.Reference
+}
+
+// staticModule is a parsed module directory.
+type staticModule struct {
+	prefix   string
+	contents []*hcl.BodyContent
+	// localCalls maps module call names to the resolved directory of their local source.
+	localCalls map[string]string
+}
+
+// staticObject is a graph node together with where it is declared and the references in its configuration.
+type staticObject struct {
+	address    string
+	rng        hcl.Range
+	references []staticReference
+	extra      []staticTarget
+}
+
+// staticTarget is a graph address that an expression refers to and the attribute it reads, if any.
+type staticTarget struct {
+	address   string
+	attribute string
+}
+
+// staticReference is a traversal in the expression of an argument.
+type staticReference struct {
+	argument  string // Argument name; arguments of nested blocks are prefixed with the block type, e.g. ingress.cidr_blocks
+	traversal hcl.Traversal
+}
+
+// parseModule adds the nodes and edges for the module in dir. inputs maps the module's input variables to the
+// addresses referenced by the calling module's arguments.
+func (p *staticParser) parseModule(ctx context.Context, prefix, dir string, inputs map[string][]staticTarget, depth int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if depth > maxModuleDepth {
+		return fmt.Errorf("%w: %s", errModuleDepthExceeded, dir)
+	}
+
+	if p.verbose {
+		utils.LogVerbose("Parsing Terraform configuration in %s", dir)
+	}
+
+	mod, err := p.loadModule(prefix, dir)
+	if err != nil {
+		return err
+	}
+
+	objects := mod.objects(inputs)
+	for _, obj := range objects {
+		n := p.builder.AddNode(obj.address)
+		n.Source = &model.SourceLocation{Filename: obj.rng.Filename, Line: obj.rng.Start.Line}
+	}
+	for _, obj := range objects {
+		for _, target := range obj.extra {
+			p.edges = append(p.edges, staticEdge{from: obj.address, to: target.address, ref: model.Reference{To: target.attribute}})
+		}
+		for _, ref := range obj.references {
+			if to, attribute := mod.resolve(ref.traversal); to != "" {
+				p.edges = append(p.edges, staticEdge{from: obj.address, to: to, ref: model.Reference{From: ref.argument, To: attribute}})
+			}
+		}
+	}
+
+	for _, content := range mod.contents {
+		for _, block := range content.Blocks.OfType("module") {
+			childDir, ok := mod.localCalls[block.Labels[0]]
+			if !ok {
+				continue
+			}
+			childPrefix := prefix + "module." + block.Labels[0] + "."
+			if err := p.parseModule(ctx, childPrefix, childDir, mod.moduleInputs(block), depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadModule parses every Terraform configuration file in dir.
+func (p *staticParser) loadModule(prefix, dir string) (*staticModule, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	mod := &staticModule{prefix: prefix, localCalls: make(map[string]string)}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		var (
+			file  *hcl.File
+			diags hcl.Diagnostics
+		)
+		switch {
+		case strings.HasSuffix(entry.Name(), ".tf.json"):
+			file, diags = p.parser.ParseJSONFile(path)
+		case strings.HasSuffix(entry.Name(), ".tf"):
+			file, diags = p.parser.ParseHCLFile(path)
+		default:
+			continue
+		}
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("%w: %w", errParseHCL, diags)
+		}
+
+		content, _, diags := file.Body.PartialContent(staticRootSchema)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("%w: %w", errParseHCL, diags)
+		}
+		mod.contents = append(mod.contents, content)
+
+		for _, block := range content.Blocks.OfType("module") {
+			if source := moduleSource(block); isLocalModuleSource(source) {
+				mod.localCalls[block.Labels[0]] = filepath.Join(dir, source)
+			}
+		}
+	}
+
+	return mod, nil
+}
+
+// objects returns the graph objects declared in the module.
+func (m *staticModule) objects(inputs map[string][]staticTarget) []staticObject {
+	var objects []staticObject
+	for _, content := range m.contents {
+		for _, block := range content.Blocks {
+			objects = append(objects, m.blockObjects(block, inputs)...)
+		}
+	}
+	return objects
+}
+
+func (m *staticModule) blockObjects(block *hcl.Block, inputs map[string][]staticTarget) []staticObject {
+	switch block.Type {
+	case "resource":
+		return []staticObject{{address: m.prefix + block.Labels[0] + "." + block.Labels[1], rng: block.DefRange, references: bodyReferences(block.Body, "")}}
+	case "data":
+		return []staticObject{{address: m.prefix + "data." + block.Labels[0] + "." + block.Labels[1], rng: block.DefRange, references: bodyReferences(block.Body, "")}}
+	case "module":
+		return []staticObject{{address: m.prefix + "module." + block.Labels[0], rng: block.DefRange, references: bodyReferences(block.Body, "")}}
+	case "variable":
+		return []staticObject{{address: m.prefix + "var." + block.Labels[0], rng: block.DefRange, extra: inputs[block.Labels[0]]}}
+	case "output":
+		return []staticObject{{address: m.prefix + "output." + block.Labels[0], rng: block.DefRange, references: bodyReferences(block.Body, "")}}
+	case "locals":
+		attrs, _ := block.Body.JustAttributes()
+		objects := make([]staticObject, 0, len(attrs))
+		for _, name := range slices.Sorted(maps.Keys(attrs)) {
+			objects = append(objects, staticObject{address: m.prefix + "local." + name, rng: attrs[name].NameRange, references: exprReferences("", attrs[name].Expr)})
+		}
+		return objects
+	default:
+		return nil
+	}
+}
+
+// moduleInputs resolves the input variable arguments of a module block to the addresses and attributes they
+// reference.
+func (m *staticModule) moduleInputs(block *hcl.Block) map[string][]staticTarget {
+	attrs, _ := block.Body.JustAttributes()
+	inputs := make(map[string][]staticTarget, len(attrs))
+	for name, attr := range attrs {
+		if moduleMetaArguments[name] {
+			continue
+		}
+		for _, traversal := range attr.Expr.Variables() {
+			if to, attribute := m.resolve(traversal); to != "" {
+				inputs[name] = append(inputs[name], staticTarget{address: to, attribute: attribute})
+			}
+		}
+	}
+	return inputs
+}
+
+// resolve returns the graph address that a traversal refers to and, for resources and data sources, the
+// attribute it reads. The address is empty for references that do not correspond to a graph node (e.g. each,
+// count, path and self).
+func (m *staticModule) resolve(traversal hcl.Traversal) (address, attribute string) {
+	names := traversalNames(traversal)
+	if len(names) < 2 {
+		return "", ""
+	}
+
+	switch names[0] {
+	case "var", "local":
+		return m.prefix + names[0] + "." + names[1], ""
+	case "data":
+		if len(names) < 3 {
+			return "", ""
+		}
+		return m.prefix + "data." + names[1] + "." + names[2], nameAt(names, 3)
+	case "module":
+		if _, ok := m.localCalls[names[1]]; ok && len(names) > 2 {
+			return m.prefix + "module." + names[1] + ".output." + names[2], ""
+		}
+		return m.prefix + "module." + names[1], ""
+	case "each", "count", "path", "self", "terraform":
+		return "", ""
+	default:
+		return m.prefix + names[0] + "." + names[1], nameAt(names, 2)
+	}
+}
+
+// nameAt returns names[i], or "" when names is shorter.
+func nameAt(names []string, i int) string {
+	if i < len(names) {
+		return names[i]
+	}
+	return ""
+}
+
+// traversalNames returns the root name and attribute names of a traversal, skipping index steps.
+func traversalNames(traversal hcl.Traversal) []string {
+	names := make([]string, 0, len(traversal))
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, s.Name)
+		case hcl.TraverseAttr:
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// bodyReferences returns every variable traversal in body, including those in nested blocks, with the argument
+// it appears in. Argument names are prefixed with prefix.
+func bodyReferences(body hcl.Body, prefix string) []staticReference {
+	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
+		var refs []staticReference
+		for _, name := range slices.Sorted(maps.Keys(syntaxBody.Attributes)) {
+			refs = append(refs, exprReferences(prefix+name, syntaxBody.Attributes[name].Expr)...)
+		}
+		for _, block := range syntaxBody.Blocks {
+			refs = append(refs, bodyReferences(block.Body, prefix+block.Type+".")...)
+		}
+		return refs
+	}
+
+	// JSON bodies have no static block structure; their attribute expressions walk nested objects instead.
+	attrs, _ := body.JustAttributes()
+	var refs []staticReference
+	for _, name := range slices.Sorted(maps.Keys(attrs)) {
+		refs = append(refs, exprReferences(prefix+name, attrs[name].Expr)...)
+	}
+	return refs
+}
+
+// exprReferences returns the variable traversals in expr as references from argument.
+func exprReferences(argument string, expr hcl.Expression) []staticReference {
+	traversals := expr.Variables()
+	refs := make([]staticReference, len(traversals))
+	for i, traversal := range traversals {
+		refs[i] = staticReference{argument: argument, traversal: traversal}
+	}
+	return refs
+}
+
+// moduleSource returns the literal source argument of a module block.
+func moduleSource(block *hcl.Block) string {
+	attrs, _ := block.Body.JustAttributes()
+	attr, ok := attrs["source"]
+	if !ok {
+		return ""
+	}
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !value.Type().Equals(cty.String) || value.IsNull() {
+		return ""
+	}
+	return value.AsString()
+}
+
+// isLocalModuleSource reports whether source refers to a module on the local filesystem.
+func isLocalModuleSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
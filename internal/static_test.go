@@ -0,0 +1,59 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseStatic(t *testing.T) {
+	graph, err := ParseStatic(context.Background(), "testdata/static", false)
+	if err != nil {
+		t.Fatalf("ParseStatic() error = %v", err)
+	}
+
+	diagram, err := GenerateMermaidFlowchart(context.Background(), graph, "TD", "", false, nil, false)
+	if err != nil {
+		t.Fatalf("GenerateMermaidFlowchart() error = %v", err)
+	}
+
+	wantLines := []string{
+		"aws_vpc_main --> var_cidr_block",
+		"aws_vpc_main --> local_tags",
+		"aws_subnet_private --> aws_vpc_main",
+		"module_app --> aws_subnet_private",
+		"module_app --> data_aws_ami_ubuntu",
+		"module_app_var_subnet_id --> aws_subnet_private",
+		"module_app_aws_instance_web --> module_app_var_ami",
+		"module_app_output_public_ip --> module_app_aws_instance_web",
+		"output_web_ip --> module_app_output_public_ip",
+		`module_remote["module.remote"]`,
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(diagram, want) {
+			t.Errorf("diagram missing %q\n%s", want, diagram)
+		}
+	}
+}
+
+func TestIsLocalModuleSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"./modules/app", true},
+		{"../shared", true},
+		{"terraform-aws-modules/vpc/aws", false},
+		{"git::https://example.com/vpc.git", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLocalModuleSource(tt.source); got != tt.want {
+			t.Errorf("isLocalModuleSource(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
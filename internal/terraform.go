@@ -0,0 +1,196 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+// Init modes selectable with --init.
+const (
+	InitUpgrade      = "upgrade"
+	InitStandard     = "standard"
+	InitBackendFalse = "backend-false"
+	InitNone         = "none"
+)
+
+// LockfileReadonly is the only supported -lockfile mode.
+const LockfileReadonly = "readonly"
+
+// Graph types selectable with --graph-type, passed to terraform graph as -type.
+const (
+	GraphTypePlan            = "plan"
+	GraphTypePlanDestroy     = "plan-destroy"
+	GraphTypePlanRefreshOnly = "plan-refresh-only"
+	GraphTypeApply           = "apply"
+)
+
+var validGraphTypes = map[string]bool{GraphTypePlan: true, GraphTypePlanDestroy: true, GraphTypePlanRefreshOnly: true, GraphTypeApply: true}
+
+// TerraformOptions configures how Terramaid runs the Terraform binary.
+type TerraformOptions struct {
+	WorkingDir     string   // Directory containing the Terraform configuration
+	Binary         string   // Path to the terraform or tofu binary
+	PlanFile       string   // Optional plan file to graph
+	Plan           bool     // Runs a speculative plan and graphs it instead of PlanFile
+	VarFiles       []string // Passed to the speculative plan as -var-file
+	Vars           []string // name=value assignments passed to the speculative plan as -var
+	GraphType      string   // Optional terraform graph -type; empty uses Terraform's default
+	DrawCycles     bool     // Passes -draw-cycles to terraform graph
+	Init           string   // One of InitUpgrade (default), InitStandard, InitBackendFalse or InitNone
+	PluginDirs     []string // Passed to init as -plugin-dir
+	Lockfile       string   // Passed to init as -lockfile; only LockfileReadonly is supported, which disables -upgrade
+	PluginCacheDir string   // Exported to init as TF_PLUGIN_CACHE_DIR
+	Verbose        bool
+}
+
+// newTerraform creates a terraform-exec handle for opts and initializes the working directory according to opts.Init.
+func newTerraform(ctx context.Context, opts TerraformOptions) (*tfexec.Terraform, error) {
+	if opts.Verbose {
+		utils.LogVerbose("Initializing Terraform with working directory: %s", opts.WorkingDir)
+		utils.LogVerbose("Using Terraform binary: %s", opts.Binary)
+	}
+
+	tf, err := tfexec.NewTerraform(opts.WorkingDir, opts.Binary)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := initTerraform(ctx, tf, opts); err != nil {
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+// initTerraform runs terraform init as described by opts.
+func initTerraform(ctx context.Context, tf *tfexec.Terraform, opts TerraformOptions) error {
+	switch opts.Init {
+	case InitNone:
+		if opts.Verbose {
+			utils.LogVerbose("Skipping terraform init")
+		}
+		return nil
+	case "", InitUpgrade, InitStandard, InitBackendFalse:
+	default:
+		return fmt.Errorf("%w %q: valid options are %s, %s, %s, %s", errInvalidInitMode, opts.Init, InitUpgrade, InitStandard, InitBackendFalse, InitNone)
+	}
+
+	if opts.Lockfile != "" && opts.Lockfile != LockfileReadonly {
+		return fmt.Errorf("%w %q: only %s is supported", errInvalidLockfileMode, opts.Lockfile, LockfileReadonly)
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("Running %s %s", opts.Binary, strings.Join(opts.initArgs(), " "))
+	}
+
+	// terraform-exec exposes neither -lockfile nor per-command environment variables, so init is run directly
+	// when either is needed.
+	if opts.Lockfile != "" || opts.PluginCacheDir != "" {
+		return execInit(ctx, opts)
+	}
+
+	initOpts := []tfexec.InitOption{tfexec.Upgrade(opts.upgrade())}
+	if opts.Init == InitBackendFalse {
+		initOpts = append(initOpts, tfexec.Backend(false))
+	}
+	for _, dir := range opts.PluginDirs {
+		initOpts = append(initOpts, tfexec.PluginDir(dir))
+	}
+
+	return tf.Init(ctx, initOpts...)
+}
+
+// upgrade reports whether init upgrades providers and modules. Terraform rejects -upgrade together with
+// -lockfile=readonly, which forbids the lock file changes an upgrade makes, so a readonly lock file disables it.
+func (o TerraformOptions) upgrade() bool {
+	return (o.Init == "" || o.Init == InitUpgrade) && o.Lockfile != LockfileReadonly
+}
+
+// initArgs returns the terraform init arguments for opts.
+func (o TerraformOptions) initArgs() []string {
+	args := []string{"init", "-input=false", "-no-color"}
+	if o.upgrade() {
+		args = append(args, "-upgrade")
+	}
+	if o.Init == InitBackendFalse {
+		args = append(args, "-backend=false")
+	}
+	for _, dir := range o.PluginDirs {
+		args = append(args, "-plugin-dir="+dir)
+	}
+	if o.Lockfile != "" {
+		args = append(args, "-lockfile="+o.Lockfile)
+	}
+	return args
+}
+
+// execInit runs terraform init without terraform-exec.
+func execInit(ctx context.Context, opts TerraformOptions) error {
+	// #nosec G204 -- the binary is the user-selected Terraform or OpenTofu executable
+	cmd := exec.CommandContext(ctx, opts.Binary, opts.initArgs()...)
+	cmd.Dir = opts.WorkingDir
+	cmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1")
+	if opts.PluginCacheDir != "" {
+		cmd.Env = append(cmd.Env, "TF_PLUGIN_CACHE_DIR="+opts.PluginCacheDir)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %w: %s", errTerraformInit, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// speculativePlan runs `terraform plan -refresh=false` into a temporary plan file and returns its path together with
+// a cleanup function that removes it. The cleanup function must be called even if later steps fail.
+func speculativePlan(ctx context.Context, tf *tfexec.Terraform, opts TerraformOptions) (string, func(), error) {
+	planOpts := []tfexec.PlanOption{tfexec.Refresh(false)}
+	for _, file := range opts.VarFiles {
+		planOpts = append(planOpts, tfexec.VarFile(file))
+	}
+	for _, assignment := range opts.Vars {
+		if name, _, ok := strings.Cut(assignment, "="); !ok || name == "" {
+			return "", nil, fmt.Errorf("%w %q: expected name=value", errInvalidVariable, assignment)
+		}
+		planOpts = append(planOpts, tfexec.Var(assignment))
+	}
+
+	file, err := os.CreateTemp("", "terramaid-*.tfplan")
+	if err != nil {
+		return "", nil, err
+	}
+	path := file.Name()
+	cleanup := func() {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) && opts.Verbose {
+			utils.LogVerbose("Could not remove temporary plan %s: %v", path, err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("Running speculative terraform plan into %s", path)
+	}
+	if _, err := tf.Plan(ctx, append(planOpts, tfexec.Out(path))...); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("%w: %w", errSpeculativePlan, err)
+	}
+
+	return path, cleanup, nil
+}
@@ -0,0 +1,220 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInitArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts TerraformOptions
+		want []string
+	}{
+		{
+			name: "default upgrades",
+			want: []string{"init", "-input=false", "-no-color", "-upgrade"},
+		},
+		{
+			name: "standard",
+			opts: TerraformOptions{Init: InitStandard},
+			want: []string{"init", "-input=false", "-no-color"},
+		},
+		{
+			name: "backend false",
+			opts: TerraformOptions{Init: InitBackendFalse},
+			want: []string{"init", "-input=false", "-no-color", "-backend=false"},
+		},
+		{
+			name: "readonly lockfile disables upgrade",
+			opts: TerraformOptions{Lockfile: LockfileReadonly},
+			want: []string{"init", "-input=false", "-no-color", "-lockfile=readonly"},
+		},
+		{
+			name: "plugin dirs and readonly lockfile",
+			opts: TerraformOptions{Init: InitStandard, PluginDirs: []string{"/a", "/b"}, Lockfile: LockfileReadonly},
+			want: []string{"init", "-input=false", "-no-color", "-plugin-dir=/a", "-plugin-dir=/b", "-lockfile=readonly"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.initArgs(); !slices.Equal(got, tt.want) {
+				t.Errorf("initArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitTerraform(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake engine binaries are shell scripts")
+	}
+
+	dir := t.TempDir()
+	record := filepath.Join(dir, "init.log")
+	binary := filepath.Join(dir, "terraform")
+	script := "#!/bin/sh\necho \"$@ cache=$TF_PLUGIN_CACHE_DIR\" > " + record + "\n"
+	if err := os.WriteFile(binary, []byte(script), 0o700); err != nil { // #nosec G306 -- test binary must be executable
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    TerraformOptions
+		wantErr error
+		want    string
+	}{
+		{
+			name: "none skips init",
+			opts: TerraformOptions{Init: InitNone, Lockfile: LockfileReadonly},
+		},
+		{
+			name:    "invalid init mode",
+			opts:    TerraformOptions{Init: "always"},
+			wantErr: errInvalidInitMode,
+		},
+		{
+			name:    "invalid lockfile mode",
+			opts:    TerraformOptions{Lockfile: "update"},
+			wantErr: errInvalidLockfileMode,
+		},
+		{
+			name: "plugin cache dir is exported",
+			opts: TerraformOptions{Init: InitStandard, Lockfile: LockfileReadonly, PluginCacheDir: "/cache"},
+			want: "init -input=false -no-color -lockfile=readonly cache=/cache",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(record)
+			tt.opts.WorkingDir = dir
+			tt.opts.Binary = binary
+
+			err := initTerraform(context.Background(), nil, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("initTerraform() error = %v, want %v", err, tt.wantErr)
+			}
+
+			data, err := os.ReadFile(record)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("init ran unexpectedly: %s", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("init did not run: %v", err)
+			}
+			if got := strings.TrimSpace(string(data)); got != tt.want {
+				t.Errorf("init ran with %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakePlanEngineScript records the plan arguments, writes the -out file and graphs it only if it exists.
+const fakePlanEngineScript = `#!/bin/sh
+dir="$(dirname "$0")"
+case "$1" in
+version)
+  echo '{"terraform_version":"1.9.5","platform":"linux_amd64","provider_selections":{}}'
+  ;;
+init)
+  ;;
+plan)
+  echo "$@" > "$dir/plan.log"
+  for arg; do
+    case "$arg" in -out=*) echo plan > "${arg#-out=}" ;; esac
+  done
+  ;;
+graph)
+  for arg; do
+    case "$arg" in -plan=*) plan="${arg#-plan=}" ;; esac
+  done
+  [ -f "$plan" ] || exit 1
+  echo "$plan" > "$dir/graph.log"
+  echo 'digraph G { "aws_instance.web[0]" [label="aws_instance.web[0]"]; }'
+  ;;
+*)
+  exit 1
+  ;;
+esac
+`
+
+func TestParseTerraform_SpeculativePlan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake engine binaries are shell scripts")
+	}
+
+	tests := []struct {
+		name     string
+		vars     []string
+		wantArgs []string
+		wantErr  error
+	}{
+		{
+			name:     "var files and vars",
+			vars:     []string{"env=prod", `tags=["a","b"]`},
+			wantArgs: []string{"-refresh=false", "-var-file=prod.tfvars", "-var env=prod", `-var tags=["a","b"]`},
+		},
+		{
+			name:    "invalid var",
+			vars:    []string{"=prod"},
+			wantErr: errInvalidVariable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			binary := filepath.Join(dir, "terraform")
+			if err := os.WriteFile(binary, []byte(fakePlanEngineScript), 0o700); err != nil { // #nosec G306 -- test binary must be executable
+				t.Fatal(err)
+			}
+
+			opts := TerraformOptions{WorkingDir: dir, Binary: binary, Plan: true, VarFiles: []string{"prod.tfvars"}, Vars: tt.vars}
+			graph, err := ParseTerraform(context.Background(), opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseTerraform() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTerraform() error = %v", err)
+			}
+			if len(graph.Nodes) != 1 {
+				t.Errorf("ParseTerraform() nodes = %d, want 1", len(graph.Nodes))
+			}
+
+			args, err := os.ReadFile(filepath.Join(dir, "plan.log"))
+			if err != nil {
+				t.Fatalf("plan did not run: %v", err)
+			}
+			for _, want := range tt.wantArgs {
+				if !strings.Contains(string(args), want) {
+					t.Errorf("plan args %q do not contain %q", args, want)
+				}
+			}
+
+			planFile, err := os.ReadFile(filepath.Join(dir, "graph.log"))
+			if err != nil {
+				t.Fatalf("graph did not read the plan: %v", err)
+			}
+			if _, err := os.Stat(strings.TrimSpace(string(planFile))); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("temporary plan %s was not removed", planFile)
+			}
+		})
+	}
+}
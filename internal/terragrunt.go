@@ -0,0 +1,400 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// terragruntConfigFile is the file that marks a directory as a Terragrunt unit.
+const terragruntConfigFile = "terragrunt.hcl"
+
+// discoverySkipDirs are tool-managed directories that never contain Terragrunt units or root modules of their own.
+var discoverySkipDirs = map[string]bool{".terragrunt-cache": true, ".terraform": true, ".git": true}
+
+// terragruntSchema describes the parts of terragrunt.hcl that define a unit's dependencies.
+var terragruntSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{{Name: "inputs"}},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "dependency", LabelNames: []string{"name"}},
+		{Type: "dependencies"},
+		{Type: "terraform"},
+	},
+}
+
+// TerragruntStack is a tree of Terragrunt units discovered under a root directory.
+type TerragruntStack struct {
+	Units []*TerragruntUnit
+}
+
+// TerragruntUnit is a directory containing a terragrunt.hcl file.
+type TerragruntUnit struct {
+	Path         string // Slash-separated path relative to the stack root
+	Dir          string // Directory on disk
+	Source       string // terraform.source, if set
+	Dependencies []TerragruntDependency
+	Graph        *model.Graph // Internal resource graph, set when the stack is expanded
+}
+
+// TerragruntDependency is an edge from a unit to a unit it depends on.
+type TerragruntDependency struct {
+	Name    string   // dependency block name; empty for dependencies.paths entries
+	Path    string   // Slash-separated path of the dependency relative to the stack root
+	Outputs []string // Outputs of the dependency referenced by the unit's inputs
+}
+
+// DiscoverTerragruntStack finds every Terragrunt unit under root and parses its dependency and dependencies blocks.
+// A terragrunt.hcl directly in root is treated as shared root configuration when units exist below it. When expand
+// is true, each unit's Terraform configuration (or local terraform.source) is parsed statically into Unit.Graph.
+func DiscoverTerragruntStack(ctx context.Context, root string, expand bool, verbose bool) (*TerragruntStack, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, err := findTerragruntDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) > 1 {
+		dirs = slices.DeleteFunc(dirs, func(dir string) bool { return dir == root })
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("%w %q", errNoTerragruntUnits, root)
+	}
+
+	parser := hclparse.NewParser()
+	stack := &TerragruntStack{}
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		unit, err := parseTerragruntUnit(parser, root, dir, verbose)
+		if err != nil {
+			return nil, err
+		}
+		if verbose {
+			utils.LogVerbose("Discovered Terragrunt unit %s with %d dependencies", unit.Path, len(unit.Dependencies))
+		}
+		if expand {
+			unit.Graph = expandTerragruntUnit(ctx, unit, verbose)
+		}
+		stack.Units = append(stack.Units, unit)
+	}
+
+	return stack, nil
+}
+
+// findTerragruntDirs returns the sorted directories under root that contain a terragrunt.hcl file.
+func findTerragruntDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && discoverySkipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == terragruntConfigFile {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// parseTerragruntUnit parses the terraform, dependency and dependencies blocks of the terragrunt.hcl file in dir.
+// Dependencies whose config_path cannot be evaluated statically are skipped, and blocks pulled in by include are
+// not followed.
+func parseTerragruntUnit(parser *hclparse.Parser, root, dir string, verbose bool) (*TerragruntUnit, error) {
+	file, diags := parser.ParseHCLFile(filepath.Join(dir, terragruntConfigFile))
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("%w: %w", errParseTerragrunt, diags)
+	}
+
+	content, _, diags := file.Body.PartialContent(terragruntSchema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("%w: %w", errParseTerragrunt, diags)
+	}
+
+	unit := &TerragruntUnit{Path: stackPath(root, dir), Dir: dir}
+	evalCtx := terragruntEvalContext(dir)
+	outputs := dependencyOutputs(content)
+
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "terraform":
+			unit.Source = stringAttribute(block.Body, "source", evalCtx)
+		case "dependency":
+			name := block.Labels[0]
+			configPath := stringAttribute(block.Body, "config_path", evalCtx)
+			if configPath == "" {
+				if verbose {
+					utils.LogVerbose("Skipping dependency %q of Terragrunt unit %s: config_path cannot be evaluated", name, unit.Path)
+				}
+				continue
+			}
+			unit.Dependencies = append(unit.Dependencies, TerragruntDependency{
+				Name:    name,
+				Path:    stackPath(root, resolvePath(dir, configPath)),
+				Outputs: outputs[name],
+			})
+		case "dependencies":
+			for _, path := range stringListAttribute(block.Body, "paths", evalCtx) {
+				unit.Dependencies = append(unit.Dependencies, TerragruntDependency{Path: stackPath(root, resolvePath(dir, path))})
+			}
+		}
+	}
+
+	return unit, nil
+}
+
+// dependencyOutputs maps each dependency name to the sorted outputs referenced as dependency.<name>.outputs.<output>
+// in the unit's inputs.
+func dependencyOutputs(content *hcl.BodyContent) map[string][]string {
+	outputs := make(map[string][]string)
+	attr, ok := content.Attributes["inputs"]
+	if !ok {
+		return outputs
+	}
+
+	for _, traversal := range attr.Expr.Variables() {
+		names := traversalNames(traversal)
+		if len(names) < 4 || names[0] != "dependency" || names[2] != "outputs" {
+			continue
+		}
+		if !slices.Contains(outputs[names[1]], names[3]) {
+			outputs[names[1]] = append(outputs[names[1]], names[3])
+		}
+	}
+	for name := range outputs {
+		sort.Strings(outputs[name])
+	}
+	return outputs
+}
+
+// expandTerragruntUnit statically parses the unit's Terraform configuration. Units whose configuration comes from
+// a remote source cannot be expanded and return nil.
+func expandTerragruntUnit(ctx context.Context, unit *TerragruntUnit, verbose bool) *model.Graph {
+	dir := unit.Dir
+	if !hasTerraformFiles(dir) {
+		if !isLocalModuleSource(unit.Source) && !filepath.IsAbs(unit.Source) {
+			if verbose {
+				utils.LogVerbose("Not expanding Terragrunt unit %s: source %q is not local", unit.Path, unit.Source)
+			}
+			return nil
+		}
+		// Terragrunt uses "//" to separate the repository root from the module subdirectory.
+		dir = resolvePath(unit.Dir, strings.Replace(unit.Source, "//", "/", 1))
+	}
+
+	graph, err := ParseStatic(ctx, dir, verbose)
+	if err != nil {
+		if verbose {
+			utils.LogVerbose("Not expanding Terragrunt unit %s: %v", unit.Path, err)
+		}
+		return nil
+	}
+	return graph
+}
+
+// hasTerraformFiles reports whether dir itself contains .tf or .tf.json files.
+func hasTerraformFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".tf") || strings.HasSuffix(entry.Name(), ".tf.json")) {
+			return true
+		}
+	}
+	return false
+}
+
+// terragruntEvalContext provides the Terragrunt functions commonly used in dependency paths.
+func terragruntEvalContext(dir string) *hcl.EvalContext {
+	terragruntDir := function.New(&function.Spec{
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(_ []cty.Value, _ cty.Type) (cty.Value, error) {
+			return cty.StringVal(dir), nil
+		},
+	})
+	return &hcl.EvalContext{Functions: map[string]function.Function{"get_terragrunt_dir": terragruntDir}}
+}
+
+// stringAttribute evaluates a string attribute of body, returning an empty string when it is absent or not
+// a known string.
+func stringAttribute(body hcl.Body, name string, evalCtx *hcl.EvalContext) string {
+	attrs, _ := body.JustAttributes()
+	attr, ok := attrs[name]
+	if !ok {
+		return ""
+	}
+	value, diags := attr.Expr.Value(evalCtx)
+	if diags.HasErrors() || value.IsNull() || !value.IsKnown() || !value.Type().Equals(cty.String) {
+		return ""
+	}
+	return value.AsString()
+}
+
+// stringListAttribute evaluates a list of strings attribute of body, skipping elements that are not known strings.
+func stringListAttribute(body hcl.Body, name string, evalCtx *hcl.EvalContext) []string {
+	attrs, _ := body.JustAttributes()
+	attr, ok := attrs[name]
+	if !ok {
+		return nil
+	}
+	value, diags := attr.Expr.Value(evalCtx)
+	if diags.HasErrors() || value.IsNull() || !value.IsKnown() || !value.CanIterateElements() {
+		return nil
+	}
+
+	var out []string
+	for it := value.ElementIterator(); it.Next(); {
+		_, elem := it.Element()
+		if elem.IsKnown() && !elem.IsNull() && elem.Type().Equals(cty.String) {
+			out = append(out, elem.AsString())
+		}
+	}
+	return out
+}
+
+// resolvePath resolves path relative to dir unless it is already absolute.
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
+
+// stackPath returns dir relative to root with forward slashes.
+func stackPath(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// GenerateTerragruntFlowchart renders a Terragrunt stack as a Mermaid flowchart in which each unit is a subgraph and
+// edges between units are labelled with the dependency outputs the unit consumes. Expanded units contain their
+// internal resource graph, which honours resourcesOnly and filter like GenerateMermaidFlowchart.
+func GenerateTerragruntFlowchart(ctx context.Context, stack *TerragruntStack, direction string, subgraphName string, resourcesOnly bool, filter *FilterConfig, verbose bool) (string, error) {
+	if !validDirections[direction] {
+		return "", fmt.Errorf("%w %s: valid options are TB, TD, BT, RL, LR", errInvalidDirection, direction)
+	}
+
+	filter = normalizeFilter(filter)
+	logFlowchartOptions(direction, subgraphName, filter, verbose)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "```mermaid\nflowchart %s\n", direction)
+
+	if subgraphName != "" {
+		fmt.Fprintf(&sb, "    subgraph %s\n", subgraphName)
+	}
+
+	units := make(map[string]string, len(stack.Units))
+	taken := make(map[string]bool, len(stack.Units))
+	for _, unit := range stack.Units {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		// Paths such as a-b/c and a_b/c clean to the same ID; later units get a numeric suffix.
+		base := terragruntUnitID(unit.Path)
+		unitID := base
+		for i := 2; taken[unitID]; i++ {
+			unitID = fmt.Sprintf("%s_%d", base, i)
+		}
+		taken[unitID] = true
+		units[unit.Path] = unitID
+		fmt.Fprintf(&sb, "    subgraph %s[\"%s\"]\n", unitID, unit.Path)
+		appendTerragruntUnit(&sb, unit, unitID, resourcesOnly, filter, verbose)
+		sb.WriteString("    end\n")
+	}
+
+	if subgraphName != "" {
+		sb.WriteString("    end\n")
+	}
+
+	for _, unit := range stack.Units {
+		for _, dep := range unit.Dependencies {
+			toID, ok := units[dep.Path]
+			if !ok {
+				if verbose {
+					utils.LogVerbose("Skipping dependency of %s on %s: not a unit in this stack", unit.Path, dep.Path)
+				}
+				continue
+			}
+			if len(dep.Outputs) > 0 {
+				fmt.Fprintf(&sb, "    %s -->|%s| %s\n", units[unit.Path], strings.Join(dep.Outputs, ", "), toID)
+			} else {
+				fmt.Fprintf(&sb, "    %s --> %s\n", units[unit.Path], toID)
+			}
+		}
+	}
+
+	sb.WriteString("```\n")
+
+	if verbose {
+		utils.LogVerbose("Terragrunt diagram generation complete with %d units", len(stack.Units))
+	}
+
+	return sb.String(), nil
+}
+
+// appendTerragruntUnit writes the body of a unit's subgraph: its internal graph when expanded, otherwise a single
+// node describing the unit's Terraform source.
+func appendTerragruntUnit(sb *strings.Builder, unit *TerragruntUnit, unitID string, resourcesOnly bool, filter *FilterConfig, verbose bool) {
+	if unit.Graph == nil {
+		label := filepath.Base(unit.Dir)
+		if unit.Source != "" {
+			label = unit.Source
+		}
+		fmt.Fprintf(sb, "        %s_source[\"%s\"]\n", unitID, model.CleanLabel(label))
+		return
+	}
+
+	state := flowchartState{
+		idPrefix:       unitID + "_",
+		addedNodes:     make(map[string]string),
+		addedProviders: make(map[string]bool),
+		resourcesOnly:  resourcesOnly,
+		filter:         filter,
+		verbose:        verbose,
+	}
+	state.selectNodes(unit.Graph)
+	state.selectEdges(unit.Graph)
+	state.writeNodes(sb)
+	state.writeEdges(sb)
+}
+
+// terragruntUnitID returns the Mermaid subgraph ID for a unit path.
+func terragruntUnitID(path string) string {
+	if path == "." {
+		return "unit_root"
+	}
+	return "unit_" + CleanID(path)
+}
@@ -0,0 +1,99 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverTerragruntStack(t *testing.T) {
+	stack, err := DiscoverTerragruntStack(context.Background(), "testdata/terragrunt", false, false)
+	if err != nil {
+		t.Fatalf("DiscoverTerragruntStack() error = %v", err)
+	}
+
+	var paths []string
+	for _, unit := range stack.Units {
+		paths = append(paths, unit.Path)
+	}
+	if got, want := strings.Join(paths, ","), "app,dns,vpc"; got != want {
+		t.Fatalf("DiscoverTerragruntStack() units = %s, want %s", got, want)
+	}
+
+	app := stack.Units[0]
+	if len(app.Dependencies) != 1 || app.Dependencies[0].Path != "vpc" {
+		t.Fatalf("app dependencies = %+v, want vpc", app.Dependencies)
+	}
+	if got := strings.Join(app.Dependencies[0].Outputs, ","); got != "private_subnet_ids,vpc_id" {
+		t.Errorf("app dependency outputs = %s, want private_subnet_ids,vpc_id", got)
+	}
+
+	dns := stack.Units[1]
+	if len(dns.Dependencies) != 2 || dns.Dependencies[0].Path != "app" || dns.Dependencies[1].Path != "vpc" {
+		t.Errorf("dns dependencies = %+v, want app and vpc", dns.Dependencies)
+	}
+}
+
+func TestGenerateTerragruntFlowchart(t *testing.T) {
+	stack, err := DiscoverTerragruntStack(context.Background(), "testdata/terragrunt", true, false)
+	if err != nil {
+		t.Fatalf("DiscoverTerragruntStack() error = %v", err)
+	}
+
+	diagram, err := GenerateTerragruntFlowchart(context.Background(), stack, "LR", "", true, nil, false)
+	if err != nil {
+		t.Fatalf("GenerateTerragruntFlowchart() error = %v", err)
+	}
+
+	wantLines := []string{
+		`subgraph unit_app["app"]`,
+		`unit_app_aws_security_group_app["aws_security_group.app"]`,
+		`unit_dns_source["tfr:///terraform-aws-modules/route53/aws?version=4.0.0"]`,
+		"unit_vpc_aws_subnet_private --> unit_vpc_aws_vpc_main",
+		"unit_app -->|private_subnet_ids, vpc_id| unit_vpc",
+		"unit_dns --> unit_app",
+		"unit_dns --> unit_vpc",
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(diagram, want) {
+			t.Errorf("diagram missing %q\n%s", want, diagram)
+		}
+	}
+	if strings.Contains(diagram, "var_cidr_block") {
+		t.Errorf("diagram contains non-resource node with resourcesOnly\n%s", diagram)
+	}
+}
+
+func TestGenerateTerragruntFlowchart_UnitIDs(t *testing.T) {
+	stack := &TerragruntStack{Units: []*TerragruntUnit{
+		{Path: ".", Dir: "live"},
+		{Path: "root", Dir: "live/root"},
+		{Path: "a-b/c", Dir: "live/a-b/c"},
+		{Path: "a_b/c", Dir: "live/a_b/c", Dependencies: []TerragruntDependency{{Path: "a-b/c"}, {Path: "root"}}},
+		{Path: "a.b/c", Dir: "live/a.b/c", Dependencies: []TerragruntDependency{{Path: "a_b/c"}}},
+	}}
+
+	diagram, err := GenerateTerragruntFlowchart(context.Background(), stack, "TD", "", false, nil, false)
+	if err != nil {
+		t.Fatalf("GenerateTerragruntFlowchart() error = %v", err)
+	}
+
+	wantLines := []string{
+		`subgraph unit_root["."]`,
+		`subgraph unit_root_2["root"]`,
+		`subgraph unit_a_b_c["a-b/c"]`,
+		`subgraph unit_a_b_c_2["a_b/c"]`,
+		`subgraph unit_a_b_c_3["a.b/c"]`,
+		"unit_a_b_c_2 --> unit_a_b_c\n",
+		"unit_a_b_c_2 --> unit_root_2\n",
+		"unit_a_b_c_3 --> unit_a_b_c_2\n",
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(diagram, want) {
+			t.Errorf("diagram missing %q\n%s", want, diagram)
+		}
+	}
+}
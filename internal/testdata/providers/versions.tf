@@ -0,0 +1,8 @@
+terraform {
+  required_providers {
+    mycloud = {
+      source  = "acme/cloud"
+      version = "~> 1.0"
+    }
+  }
+}
@@ -0,0 +1,37 @@
+variable "cidr_block" {
+  type    = string
+  default = "10.0.0.0/16"
+}
+
+locals {
+  tags = { Project = "terramaid" }
+}
+
+data "aws_ami" "ubuntu" {
+  most_recent = true
+}
+
+resource "aws_vpc" "main" {
+  cidr_block = var.cidr_block
+  tags       = local.tags
+}
+
+resource "aws_subnet" "private" {
+  count  = 2
+  vpc_id = aws_vpc.main.id
+
+  tags = {
+    Index = count.index
+  }
+}
+
+module "app" {
+  source    = "./modules/app"
+  subnet_id = aws_subnet.private[0].id
+  ami       = data.aws_ami.ubuntu.id
+}
+
+module "remote" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 5.0"
+}
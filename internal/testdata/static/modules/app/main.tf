@@ -0,0 +1,15 @@
+variable "subnet_id" {}
+variable "ami" {}
+
+resource "aws_instance" "web" {
+  ami       = var.ami
+  subnet_id = var.subnet_id
+
+  lifecycle {
+    ignore_changes = [tags]
+  }
+}
+
+output "public_ip" {
+  value = aws_instance.web.public_ip
+}
@@ -0,0 +1,20 @@
+include "root" {
+  path = find_in_parent_folders()
+}
+
+terraform {
+  source = "../modules//app"
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+
+  mock_outputs = {
+    vpc_id = "vpc-mock"
+  }
+}
+
+inputs = {
+  vpc_id     = dependency.vpc.outputs.vpc_id
+  subnet_ids = dependency.vpc.outputs.private_subnet_ids
+}
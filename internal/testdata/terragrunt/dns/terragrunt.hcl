@@ -0,0 +1,7 @@
+terraform {
+  source = "tfr:///terraform-aws-modules/route53/aws?version=4.0.0"
+}
+
+dependencies {
+  paths = ["${get_terragrunt_dir()}/../app", "../vpc"]
+}
@@ -0,0 +1,6 @@
+variable "vpc_id" {}
+variable "subnet_ids" {}
+
+resource "aws_security_group" "app" {
+  vpc_id = var.vpc_id
+}
@@ -0,0 +1,7 @@
+include "root" {
+  path = find_in_parent_folders()
+}
+
+inputs = {
+  cidr_block = "10.0.0.0/16"
+}
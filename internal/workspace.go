@@ -0,0 +1,95 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+// workspaceRestoreTimeout bounds how long restoring the original workspace may take once ctx is done.
+const workspaceRestoreTimeout = 30 * time.Second
+
+// WorkspaceGraph is the graph built for one Terraform workspace.
+type WorkspaceGraph struct {
+	Workspace string
+	Graph     *model.Graph
+}
+
+// ParseTerraformWorkspaces initializes opts.WorkingDir once and builds the graph of each workspace in workspaces,
+// or of every workspace when workspaces is empty. The workspace that was selected beforehand is restored before
+// returning, including when graphing fails or ctx is cancelled.
+func ParseTerraformWorkspaces(ctx context.Context, opts TerraformOptions, workspaces []string) (graphs []WorkspaceGraph, err error) {
+	if err := validateGraphType(opts.GraphType); err != nil {
+		return nil, err
+	}
+
+	tf, err := newTerraform(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	available, original, err := tf.WorkspaceList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Verbose {
+		utils.LogVerbose("Found workspaces %v, current workspace is %s", available, original)
+	}
+
+	if len(workspaces) == 0 {
+		workspaces = available
+	}
+	for _, workspace := range workspaces {
+		if !slices.Contains(available, workspace) {
+			return nil, fmt.Errorf("%w: %q", errWorkspaceNotFound, workspace)
+		}
+	}
+
+	defer func() {
+		if rerr := restoreWorkspace(ctx, tf, original, opts.Verbose); rerr != nil {
+			err = errors.Join(err, rerr)
+		}
+	}()
+
+	for _, workspace := range workspaces {
+		if opts.Verbose {
+			utils.LogVerbose("Selecting workspace %s", workspace)
+		}
+		if err := tf.WorkspaceSelect(ctx, workspace); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", errSelectWorkspace, workspace, err)
+		}
+
+		graph, err := terraformGraph(ctx, tf, opts)
+		if err != nil {
+			return nil, fmt.Errorf("workspace %q: %w", workspace, err)
+		}
+		graphs = append(graphs, WorkspaceGraph{Workspace: workspace, Graph: graph})
+	}
+
+	return graphs, nil
+}
+
+// restoreWorkspace selects workspace again. It uses a context detached from ctx so that the original workspace
+// is restored even after a timeout or cancellation.
+func restoreWorkspace(ctx context.Context, tf *tfexec.Terraform, workspace string, verbose bool) error {
+	restoreCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), workspaceRestoreTimeout)
+	defer cancel()
+
+	if verbose {
+		utils.LogVerbose("Restoring workspace %s", workspace)
+	}
+	if err := tf.WorkspaceSelect(restoreCtx, workspace); err != nil {
+		return fmt.Errorf("%w %q: %w", errRestoreWorkspace, workspace, err)
+	}
+
+	return nil
+}
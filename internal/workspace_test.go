@@ -0,0 +1,110 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeWorkspaceEngineScript emulates terraform workspace commands. The selected workspace is stored in the
+// "current" file and graph output contains a resource named after it; graphing the "broken" workspace fails.
+const fakeWorkspaceEngineScript = `#!/bin/sh
+state="$(dirname "$0")/current"
+[ -f "$state" ] || echo dev > "$state"
+case "$1" in
+version)
+  echo '{"terraform_version":"1.9.5","platform":"linux_amd64","provider_selections":{}}'
+  ;;
+init)
+  ;;
+workspace)
+  case "$2" in
+  list)
+    for ws in default dev broken prod; do
+      if [ "$ws" = "$(cat "$state")" ]; then echo "* $ws"; else echo "  $ws"; fi
+    done
+    ;;
+  select)
+    for arg; do ws="$arg"; done
+    echo "$ws" > "$state"
+    ;;
+  esac
+  ;;
+graph)
+  ws="$(cat "$state")"
+  [ "$ws" = broken ] && exit 1
+  echo "digraph G { \"aws_s3_bucket.$ws\" [label=\"aws_s3_bucket.$ws\"]; }"
+  ;;
+*)
+  exit 1
+  ;;
+esac
+`
+
+func TestParseTerraformWorkspaces(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake engine binaries are shell scripts")
+	}
+
+	tests := []struct {
+		name       string
+		workspaces []string
+		want       []string
+		wantErr    error
+	}{
+		{name: "single workspace", workspaces: []string{"prod"}, want: []string{"prod"}},
+		{name: "multiple workspaces", workspaces: []string{"default", "prod"}, want: []string{"default", "prod"}},
+		{name: "unknown workspace", workspaces: []string{"staging"}, wantErr: errWorkspaceNotFound},
+		{name: "graph failure", workspaces: []string{"prod", "broken"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			binary := filepath.Join(dir, "terraform")
+			if err := os.WriteFile(binary, []byte(fakeWorkspaceEngineScript), 0o700); err != nil { // #nosec G306 -- test binary must be executable
+				t.Fatal(err)
+			}
+
+			graphs, err := ParseTerraformWorkspaces(context.Background(), TerraformOptions{WorkingDir: dir, Binary: binary}, tt.workspaces)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ParseTerraformWorkspaces() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.want == nil:
+				if err == nil {
+					t.Error("ParseTerraformWorkspaces() expected error")
+				}
+			case err != nil:
+				t.Fatalf("ParseTerraformWorkspaces() error = %v", err)
+			}
+
+			var got []string
+			for _, g := range graphs {
+				got = append(got, g.Workspace)
+				if g.Graph.Node("aws_s3_bucket."+g.Workspace) == nil {
+					t.Errorf("graph for %s does not contain its workspace's resource", g.Workspace)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseTerraformWorkspaces() workspaces = %v, want %v", got, tt.want)
+			}
+
+			current, err := os.ReadFile(filepath.Join(dir, "current"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ws := strings.TrimSpace(string(current)); ws != "dev" {
+				t.Errorf("workspace after run = %s, want dev", ws)
+			}
+		})
+	}
+}
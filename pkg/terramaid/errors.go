@@ -0,0 +1,24 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package terramaid
+
+import "errors"
+
+var (
+	errCheckTerraformFiles       = errors.New("error checking Terraform files in directory")
+	errTerraformFilesDoNotExist  = errors.New("terraform files do not exist in directory")
+	errTerraformDirectoryMissing = errors.New("terraform directory does not exist")
+	errShowPlanWithoutPlanFile   = errors.New("Options.ShowPlan requires Options.TFPlan or Options.Plan")
+	errInvalidMode               = errors.New("invalid mode")
+	errEngineMismatch            = errors.New("engine does not match binary")
+	errInvalidEngine             = errors.New("invalid engine")
+	errPlanConflict              = errors.New("Options.Plan cannot be combined with Options.TFPlan or Options.StatePull")
+	errPlanRequiresTerraformMode = errors.New("Options.Plan requires Options.Mode terraform")
+	errVarsWithoutPlan           = errors.New("Options.Vars and Options.VarFiles require Options.Plan")
+	errProviderSchemaConflict    = errors.New("Options.ProviderSchema and Options.ProviderSchemaFile cannot be combined")
+	errTerragruntChartType       = errors.New("Options.Terragrunt only supports the flowchart chart type")
+	errTerragruntFormat          = errors.New("Options.Terragrunt only supports the mermaid format")
+	errDrawCyclesWithoutGraph    = errors.New("Options.DrawCycles requires Options.GraphType")
+	errWorkspaceIncompatible     = errors.New("workspaces require Options.Mode terraform and cannot be combined with Options.GraphFile, Options.PlanJSON, Options.State, Options.ShowPlan, Options.StatePull or Options.Terragrunt")
+)
@@ -0,0 +1,658 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+// Package terramaid generates diagrams from Terraform configurations, plans and state: Mermaid charts, or the
+// flowchart in another format such as PlantUML, D2, DOT, JSON, GraphML or GEXF.
+//
+// Generate runs the whole pipeline for one set of Options. LoadGraph and Render split it into building the
+// dependency graph and rendering it, so a graph can be rendered more than once. The package has no mutable
+// package-level state: every setting is read from Options, so diagrams for different configurations can be
+// generated concurrently in one process.
+package terramaid
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/RoseSecurity/terramaid/internal"
+	"github.com/RoseSecurity/terramaid/internal/model"
+	"github.com/RoseSecurity/terramaid/pkg/utils"
+)
+
+// Graph sources selectable with Options.Mode.
+const (
+	ModeTerraform = "terraform" // Run terraform graph, plan, show or state pull
+	ModeStatic    = "static"    // Parse the HCL configuration without a Terraform binary
+)
+
+// Options describes where a graph is read from and how it is rendered. The zero value renders the terraform graph
+// of the current directory, top-down.
+type Options struct {
+	WorkingDir string // Directory containing the Terraform configuration; defaults to "."
+	Mode       string // ModeTerraform (default) or ModeStatic
+
+	Terragrunt       bool // Diagram every Terragrunt unit under WorkingDir and their dependencies
+	TerragruntExpand bool // Include each Terragrunt unit's internal resource graph
+
+	GraphFile string // Pre-generated terraform graph DOT output, or "-" for stdin
+	PlanJSON  string // terraform show -json plan output, or "-" for stdin
+	State     string // Terraform state file, or "-" for stdin
+
+	TFPlan     string   // Plan file to graph
+	Plan       bool     // Graph a speculative terraform plan -refresh=false instead of TFPlan
+	VarFiles   []string // Variable files for Plan
+	Vars       []string // name=value variable assignments for Plan
+	ShowPlan   bool     // Read TFPlan or the Plan result with terraform show -json
+	StatePull  bool     // Read deployed resources with terraform state pull
+	Workspace  string   // Workspace to run terraform graph in; empty uses the selected workspace
+	GraphType  string   // terraform graph -type: plan, plan-destroy, plan-refresh-only or apply
+	DrawCycles bool     // Highlight dependency cycles; requires GraphType
+
+	TFBinary       string   // Path to the terraform or tofu binary; found on PATH for Engine when empty
+	Engine         string   // terraform, tofu or auto (default)
+	Init           string   // upgrade (default), standard, backend-false or none
+	PluginDirs     []string // Passed to terraform init -plugin-dir
+	Lockfile       string   // Passed to terraform init -lockfile; only readonly is supported, which disables the default -upgrade
+	PluginCacheDir string   // Exported to terraform init as TF_PLUGIN_CACHE_DIR
+
+	Direction    string // Diagram direction: TB, TD (default), BT, RL or LR
+	SubgraphName string // Name of the subgraph wrapping the diagram; empty for none
+	ChartType    string // Mermaid chart type, one of ChartTypes; defaults to flowchart
+	Format       string // Output format, one of Formats; defaults to mermaid. Other formats draw flowcharts
+	GroupBy      string // Grouping of architecture services and of other formats' nodes: module (default) or provider
+	IconMapFile  string // JSON object mapping resource types or glob patterns to architecture icons, or "-" for stdin
+
+	ResourcesOnly        bool           // Only include resource nodes and the edges between them
+	ProviderSchema       bool           // Classify resources with terraform providers schema -json
+	ProviderSchemaFile   string         // Cached terraform providers schema -json output, or "-" for stdin
+	ResourceTypeMatcher  *regexp.Regexp // Classifies additional resource types when no provider schema is used
+	ResourceTypePrefixes []string       // Resource type prefixes classified as resources when no provider schema is used
+
+	IncludeTypes     []string // Include only these resource types; supports glob patterns
+	ExcludeTypes     []string // Exclude these resource types; supports glob patterns
+	IncludeProviders []string // Include only resources from these providers: aws, aws.west, hashicorp/aws or hashicorp/aws.west
+	ExcludeModules   []string // Exclude resources from these modules; supports glob patterns
+
+	IncludeAttributes []string // Attributes shown in class diagrams; supports glob patterns and defaults to common attributes
+	ExcludeAttributes []string // Attributes hidden in class diagrams; supports glob patterns
+
+	// ClassificationReport, when set, receives one line per node explaining why it is kept in or dropped from
+	// the diagram.
+	ClassificationReport io.Writer
+
+	Verbose bool // Log progress with utils.LogVerbose
+}
+
+// Graph is a dependency graph built by LoadGraph, or a Terragrunt stack when Options.Terragrunt is set.
+type Graph struct {
+	graph  *model.Graph
+	stack  *internal.TerragruntStack
+	binary string // Terraform binary the graph was built with, reused to fetch provider schemas
+}
+
+// ChartType describes a diagram kind that Render can produce.
+type ChartType struct {
+	Name        string // Value for Options.ChartType
+	Description string
+}
+
+// WorkspaceGraph is the graph built for one Terraform workspace.
+type WorkspaceGraph struct {
+	Workspace string
+	Graph     *Graph
+}
+
+// Generate builds the graph described by opts and writes its diagram in opts.Format to w.
+func Generate(ctx context.Context, opts Options, w io.Writer) error {
+	// Reject invalid rendering options before building the graph, which may run Terraform.
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	graph, err := LoadGraph(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return Render(ctx, graph, opts, w)
+}
+
+// LoadGraph builds the graph described by opts. A pre-generated DOT graph, plan JSON or state file is parsed
+// directly; otherwise the working directory is validated and the graph is built statically or with the
+// Terraform binary.
+func LoadGraph(ctx context.Context, opts Options) (*Graph, error) {
+	opts = opts.withDefaults()
+	if opts.Workspace != "" {
+		if err := validateWorkspace(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Terragrunt {
+		stack, err := loadTerragrunt(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &Graph{stack: stack}, nil
+	}
+
+	switch {
+	case opts.GraphFile != "":
+		return parseGraphFile(ctx, opts)
+	case opts.PlanJSON != "":
+		return parsePlanJSON(ctx, opts)
+	case opts.State != "":
+		return parseStateFile(ctx, opts)
+	case opts.Mode != ModeTerraform && opts.Mode != ModeStatic:
+		return nil, fmt.Errorf("%w %q: valid options are %s, %s", errInvalidMode, opts.Mode, ModeTerraform, ModeStatic)
+	}
+
+	if err := validatePlanOptions(opts); err != nil {
+		return nil, err
+	}
+	if err := validateWorkingDir(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	if opts.Mode == ModeStatic {
+		return parseStatic(ctx, opts)
+	}
+
+	binary, engine, err := resolveBinary(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.TFBinary = binary
+
+	graph, err := parseTerraform(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	graph.binary = binary
+	if graph.graph.TerraformVersion == "" {
+		graph.graph.TerraformVersion = engine.Version
+	}
+	return graph, nil
+}
+
+// LoadWorkspaceGraphs initializes opts.WorkingDir once and builds the terraform graph of each workspace in
+// workspaces, or of every workspace when workspaces is empty. The previously selected workspace is restored.
+func LoadWorkspaceGraphs(ctx context.Context, opts Options, workspaces []string) ([]WorkspaceGraph, error) {
+	opts = opts.withDefaults()
+
+	if err := validateWorkspace(opts); err != nil {
+		return nil, err
+	}
+	if err := validatePlanOptions(opts); err != nil {
+		return nil, err
+	}
+	if err := validateWorkingDir(ctx, opts); err != nil {
+		return nil, err
+	}
+	binary, engine, err := resolveBinary(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.TFBinary = binary
+
+	graphs, err := internal.ParseTerraformWorkspaces(ctx, newTerraformOptions(opts), workspaces)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Terraform: %w", err)
+	}
+
+	out := make([]WorkspaceGraph, 0, len(graphs))
+	for _, wg := range graphs {
+		wg.Graph.TerraformVersion = engine.Version
+		out = append(out, WorkspaceGraph{Workspace: wg.Workspace, Graph: &Graph{graph: wg.Graph, binary: binary}})
+	}
+	return out, nil
+}
+
+// Render writes the diagram of graph in opts.Format to w, filtered and classified as described by opts.
+func Render(ctx context.Context, graph *Graph, opts Options, w io.Writer) error {
+	opts = opts.withDefaults()
+
+	diagram, err := render(ctx, graph, opts)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, diagram); err != nil {
+		return fmt.Errorf("error writing diagram: %w", err)
+	}
+	return nil
+}
+
+// ChartTypes returns the chart types Render supports, sorted by name.
+func ChartTypes() []ChartType {
+	names := internal.ChartTypes()
+	types := make([]ChartType, 0, len(names))
+	for _, name := range names {
+		r, _ := internal.LookupRenderer(name)
+		types = append(types, ChartType{Name: name, Description: r.Description()})
+	}
+	return types
+}
+
+// Formats returns the output formats Render supports, sorted.
+func Formats() []string {
+	return internal.Formats()
+}
+
+// FormatExtension returns the file extension, including the dot, of output in format, e.g. ".md" for Mermaid, or
+// "" for unknown formats.
+func FormatExtension(format string) string {
+	return internal.FormatExtension(format)
+}
+
+// ResolveBinary returns opts.TFBinary, or locates the binary for opts.Engine on PATH when it is empty. The engine
+// and version of the binary are detected; an explicit engine that contradicts the binary is an error.
+func ResolveBinary(ctx context.Context, opts Options) (string, error) {
+	binary, _, err := resolveBinary(ctx, opts)
+	return binary, err
+}
+
+// resolveBinary is ResolveBinary that also returns the detected engine, which is empty when detection failed.
+func resolveBinary(ctx context.Context, opts Options) (string, internal.Engine, error) {
+	opts = opts.withDefaults()
+
+	switch opts.Engine {
+	case internal.EngineTerraform, internal.EngineTofu, internal.EngineAuto:
+	default:
+		return "", internal.Engine{}, fmt.Errorf("%w %q: valid options are %s, %s, %s", errInvalidEngine, opts.Engine, internal.EngineTerraform, internal.EngineTofu, internal.EngineAuto)
+	}
+
+	binary := opts.TFBinary
+	if binary == "" {
+		found, err := internal.FindEngineBinary(opts.Engine)
+		if err != nil {
+			return "", internal.Engine{}, fmt.Errorf("error finding Terraform binary: %w", err)
+		}
+		binary = found
+		if opts.Verbose {
+			utils.LogVerbose("Terraform binary found at: %s", binary)
+		}
+	}
+
+	engine, err := internal.DetectEngine(ctx, binary)
+	if err != nil {
+		if opts.Verbose {
+			utils.LogVerbose("Could not detect engine for %s: %v", binary, err)
+		}
+		return binary, internal.Engine{}, nil
+	}
+
+	if opts.Engine != internal.EngineAuto && opts.Engine != engine.Name {
+		return "", internal.Engine{}, fmt.Errorf("%w: Options.Engine %s but %s is %s", errEngineMismatch, opts.Engine, binary, engine.Name)
+	}
+	if opts.Verbose {
+		utils.LogVerbose("Detected engine %s version %s", engine.Name, engine.Version)
+	}
+
+	return binary, engine, nil
+}
+
+// Validate reports options that are invalid for any graph, such as an unknown chart type or DrawCycles without
+// GraphType, which terraform graph would silently ignore.
+func (o Options) Validate() error {
+	if o.DrawCycles && o.GraphType == "" {
+		return errDrawCyclesWithoutGraph
+	}
+	if o.Workspace != "" {
+		if err := validateWorkspace(o.withDefaults()); err != nil {
+			return err
+		}
+	}
+	_, err := lookupRenderer(o.withDefaults())
+	return err
+}
+
+// RunsTerraform reports whether o builds the graph by running the Terraform binary in o.WorkingDir, rather than
+// reading GraphFile, PlanJSON or State, parsing the configuration statically or discovering a Terragrunt stack.
+func (o Options) RunsTerraform() bool {
+	o = o.withDefaults()
+	return o.Mode == ModeTerraform && !o.Terragrunt && o.GraphFile == "" && o.PlanJSON == "" && o.State == ""
+}
+
+// withDefaults returns a copy of o with empty settings replaced by their defaults.
+func (o Options) withDefaults() Options {
+	if o.WorkingDir == "" {
+		o.WorkingDir = "."
+	}
+	if o.Mode == "" {
+		o.Mode = ModeTerraform
+	}
+	if o.Engine == "" {
+		o.Engine = internal.EngineAuto
+	}
+	if o.Direction == "" {
+		o.Direction = "TD"
+	}
+	if o.ChartType == "" {
+		o.ChartType = internal.ChartTypeFlowchart
+	}
+	if o.Format == "" {
+		o.Format = internal.FormatMermaid
+	}
+	return o
+}
+
+// validatePlanOptions checks that the plan-related options are used together consistently.
+func validatePlanOptions(opts Options) error {
+	switch {
+	case opts.Plan && (opts.TFPlan != "" || opts.StatePull):
+		return errPlanConflict
+	case opts.Plan && opts.Mode != ModeTerraform:
+		return fmt.Errorf("%w: Options.Mode %s", errPlanRequiresTerraformMode, opts.Mode)
+	case !opts.Plan && (len(opts.VarFiles) > 0 || len(opts.Vars) > 0):
+		return errVarsWithoutPlan
+	case opts.ShowPlan && opts.TFPlan == "" && !opts.Plan:
+		return errShowPlanWithoutPlanFile
+	}
+	return nil
+}
+
+// validateWorkspace checks that opts builds the graph with terraform graph or plan, the only sources that
+// depend on the selected workspace.
+func validateWorkspace(opts Options) error {
+	if !opts.RunsTerraform() || opts.ShowPlan || opts.StatePull {
+		return errWorkspaceIncompatible
+	}
+	return nil
+}
+
+// validateWorkingDir checks that opts.WorkingDir exists and contains Terraform files.
+func validateWorkingDir(ctx context.Context, opts Options) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	exists, err := utils.TerraformFilesExist(opts.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("%w %q: %w", errCheckTerraformFiles, opts.WorkingDir, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w %q", errTerraformFilesDoNotExist, opts.WorkingDir)
+	}
+	if opts.Verbose {
+		utils.LogVerbose("Confirmed Terraform files exist in %s", opts.WorkingDir)
+	}
+
+	if !utils.DirExists(opts.WorkingDir) {
+		return fmt.Errorf("%w %q", errTerraformDirectoryMissing, opts.WorkingDir)
+	}
+
+	return nil
+}
+
+func parseTerraform(ctx context.Context, opts Options) (*Graph, error) {
+	if opts.Verbose {
+		utils.LogVerbose("Initializing Terraform and building graph...")
+	}
+
+	var (
+		graph *model.Graph
+		err   error
+	)
+	tfOpts := newTerraformOptions(opts)
+	switch {
+	case opts.StatePull:
+		graph, err = internal.PullTerraformState(ctx, tfOpts)
+	case opts.ShowPlan:
+		graph, err = internal.ShowTerraformPlan(ctx, tfOpts)
+	case opts.Workspace != "":
+		var graphs []internal.WorkspaceGraph
+		graphs, err = internal.ParseTerraformWorkspaces(ctx, tfOpts, []string{opts.Workspace})
+		if err == nil {
+			graph = graphs[0].Graph
+		}
+	default:
+		graph, err = internal.ParseTerraform(ctx, tfOpts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Terraform: %w", err)
+	}
+
+	// Respect context cancellation after heavy parsing
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Graph{graph: graph}, nil
+}
+
+func parseGraphFile(ctx context.Context, opts Options) (*Graph, error) {
+	graph, err := internal.ParseGraphFile(ctx, opts.GraphFile, opts.Verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing graph file: %w", err)
+	}
+
+	return &Graph{graph: graph}, nil
+}
+
+func parseStateFile(ctx context.Context, opts Options) (*Graph, error) {
+	graph, err := internal.ParseStateFile(ctx, opts.State, opts.Verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing state file: %w", err)
+	}
+
+	return &Graph{graph: graph}, nil
+}
+
+func parseStatic(ctx context.Context, opts Options) (*Graph, error) {
+	if opts.Verbose {
+		utils.LogVerbose("Parsing Terraform configuration statically...")
+	}
+
+	graph, err := internal.ParseStatic(ctx, opts.WorkingDir, opts.Verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Terraform configuration: %w", err)
+	}
+
+	return &Graph{graph: graph}, nil
+}
+
+func parsePlanJSON(ctx context.Context, opts Options) (*Graph, error) {
+	graph, err := internal.ParsePlanJSONFile(ctx, opts.PlanJSON, opts.Verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing plan JSON: %w", err)
+	}
+
+	return &Graph{graph: graph}, nil
+}
+
+func loadTerragrunt(ctx context.Context, opts Options) (*internal.TerragruntStack, error) {
+	if !utils.DirExists(opts.WorkingDir) {
+		return nil, fmt.Errorf("%w %q", errTerraformDirectoryMissing, opts.WorkingDir)
+	}
+
+	if opts.Verbose {
+		utils.LogVerbose("Discovering Terragrunt units in %s", opts.WorkingDir)
+	}
+
+	stack, err := internal.DiscoverTerragruntStack(ctx, opts.WorkingDir, opts.TerragruntExpand, opts.Verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering Terragrunt units: %w", err)
+	}
+
+	return stack, nil
+}
+
+// lookupRenderer returns the renderer for opts.Format and opts.ChartType.
+func lookupRenderer(opts Options) (internal.Renderer, error) {
+	renderer, err := internal.LookupFormat(opts.Format, opts.ChartType)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Terragrunt && opts.ChartType != internal.ChartTypeFlowchart {
+		return nil, fmt.Errorf("%w: Options.ChartType %s", errTerragruntChartType, opts.ChartType)
+	}
+	if opts.Terragrunt && opts.Format != internal.FormatMermaid {
+		return nil, fmt.Errorf("%w: Options.Format %s", errTerragruntFormat, opts.Format)
+	}
+	return renderer, nil
+}
+
+func render(ctx context.Context, graph *Graph, opts Options) (string, error) {
+	opts.Terragrunt = graph.stack != nil
+	renderer, err := lookupRenderer(opts)
+	if err != nil {
+		return "", err
+	}
+
+	if graph.stack != nil {
+		diagram, err := internal.GenerateTerragruntFlowchart(ctx, graph.stack, opts.Direction, opts.SubgraphName, opts.ResourcesOnly, newFilterConfig(opts), opts.Verbose)
+		if err != nil {
+			return "", fmt.Errorf("error generating Terragrunt stack diagram: %w", err)
+		}
+		return diagram, nil
+	}
+
+	if opts.Verbose {
+		if opts.Format == internal.FormatMermaid {
+			utils.LogVerbose("Generating Mermaid %s...", opts.ChartType)
+		} else {
+			utils.LogVerbose("Generating %s diagram...", opts.Format)
+		}
+	}
+
+	filter := newFilterConfig(opts)
+	// The JSON, GraphML and GEXF exports record the source address of each node's provider.
+	exportsProviders := opts.Format == internal.FormatJSON || opts.Format == internal.FormatGraphML || opts.Format == internal.FormatGEXF
+	if len(filter.IncludeProviders) > 0 || opts.GroupBy == internal.GroupByProvider || exportsProviders {
+		providers, err := internal.ResolveProviders(graph.graph, opts.WorkingDir, opts.Verbose)
+		if err != nil {
+			return "", fmt.Errorf("error resolving providers: %w", err)
+		}
+		filter.Providers = providers
+	}
+	schema, err := loadProviderSchema(ctx, graph, opts)
+	if err != nil {
+		return "", err
+	}
+	filter.Schema = schema
+
+	var icons map[string]string
+	if opts.IconMapFile != "" {
+		if icons, err = internal.LoadIconMap(ctx, opts.IconMapFile, opts.Verbose); err != nil {
+			return "", err
+		}
+	}
+
+	diagram, err := renderer.Render(ctx, graph.graph, internal.RenderOptions{
+		Direction:         opts.Direction,
+		SubgraphName:      opts.SubgraphName,
+		ResourcesOnly:     opts.ResourcesOnly,
+		Filter:            filter,
+		GroupBy:           opts.GroupBy,
+		IconMap:           icons,
+		IncludeAttributes: opts.IncludeAttributes,
+		ExcludeAttributes: opts.ExcludeAttributes,
+		Title:             graphTitle(opts),
+		Verbose:           opts.Verbose,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error generating %s diagram: %w", opts.Format, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if opts.ClassificationReport != nil {
+		if err := internal.WriteClassificationReport(opts.ClassificationReport, internal.ClassifyNodes(graph.graph, opts.ResourcesOnly, filter)); err != nil {
+			return "", fmt.Errorf("error writing classification report: %w", err)
+		}
+	}
+
+	return internal.AddMermaidTitle(diagram, graphTitle(opts)), nil
+}
+
+// loadProviderSchema returns the provider schema used to classify resources: read from opts.ProviderSchemaFile,
+// fetched with terraform providers schema -json when opts.ProviderSchema is set, or nil to use the heuristics.
+func loadProviderSchema(ctx context.Context, graph *Graph, opts Options) (*internal.ResourceSchema, error) {
+	switch {
+	case opts.ProviderSchema && opts.ProviderSchemaFile != "":
+		return nil, errProviderSchemaConflict
+	case opts.ProviderSchemaFile != "":
+		schema, err := internal.LoadProviderSchemaFile(ctx, opts.ProviderSchemaFile, opts.Verbose)
+		if err != nil {
+			return nil, fmt.Errorf("error loading provider schema: %w", err)
+		}
+		return schema, nil
+	case !opts.ProviderSchema:
+		return nil, nil
+	}
+
+	if opts.TFBinary == "" {
+		opts.TFBinary = graph.binary
+	}
+	if opts.TFBinary == "" {
+		binary, err := ResolveBinary(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		opts.TFBinary = binary
+	}
+
+	tfOpts := newTerraformOptions(opts)
+	// The graph was built with the same binary, so the working directory is already initialized.
+	if opts.RunsTerraform() {
+		tfOpts.Init = internal.InitNone
+	}
+
+	schema, err := internal.FetchProviderSchema(ctx, tfOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching provider schema: %w", err)
+	}
+	return schema, nil
+}
+
+// graphTitle returns the diagram title naming the terraform graph type and workspace, or "" when opts does not
+// build the graph with terraform graph or uses the default graph type and workspace.
+func graphTitle(opts Options) string {
+	if !opts.RunsTerraform() || opts.ShowPlan || opts.StatePull {
+		return ""
+	}
+
+	var parts []string
+	if opts.GraphType != "" {
+		parts = append(parts, "Graph type: "+opts.GraphType)
+	}
+	if opts.Workspace != "" {
+		parts = append(parts, "Workspace: "+opts.Workspace)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// newTerraformOptions creates the options used to run the Terraform binary from opts.
+func newTerraformOptions(opts Options) internal.TerraformOptions {
+	return internal.TerraformOptions{
+		WorkingDir:     opts.WorkingDir,
+		Binary:         opts.TFBinary,
+		PlanFile:       opts.TFPlan,
+		Plan:           opts.Plan,
+		VarFiles:       opts.VarFiles,
+		Vars:           opts.Vars,
+		GraphType:      opts.GraphType,
+		DrawCycles:     opts.DrawCycles,
+		Init:           opts.Init,
+		PluginDirs:     opts.PluginDirs,
+		Lockfile:       opts.Lockfile,
+		PluginCacheDir: opts.PluginCacheDir,
+		Verbose:        opts.Verbose,
+	}
+}
+
+// newFilterConfig creates the resource filter configuration from opts.
+func newFilterConfig(opts Options) *internal.FilterConfig {
+	return &internal.FilterConfig{
+		IncludeTypes:         opts.IncludeTypes,
+		ExcludeTypes:         opts.ExcludeTypes,
+		IncludeProviders:     opts.IncludeProviders,
+		ExcludeModules:       opts.ExcludeModules,
+		ResourceTypeMatcher:  opts.ResourceTypeMatcher,
+		ResourceTypePrefixes: opts.ResourceTypePrefixes,
+	}
+}
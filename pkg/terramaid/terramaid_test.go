@@ -0,0 +1,250 @@
+// Copyright RoseSecurity 2024, 2026
+// SPDX-License-Identifier: Apache-2.0
+
+package terramaid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testGraph = `digraph G {
+  "[root] aws_instance.web (expand)" [label = "aws_instance.web"];
+  "[root] widget.x (expand)" [label = "widget.x"];
+  "[root] var.region" [label = "var.region"];
+  "[root] aws_instance.web (expand)" -> "[root] widget.x (expand)";
+  "[root] aws_instance.web (expand)" -> "[root] var.region";
+}
+`
+
+func writeGraphFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "graph.dot")
+	if err := os.WriteFile(path, []byte(testGraph), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGenerate_Static(t *testing.T) {
+	var sb strings.Builder
+	err := Generate(context.Background(), Options{WorkingDir: filepath.Join("..", "..", "test", "aws"), Mode: ModeStatic, ResourcesOnly: true}, &sb)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(sb.String(), "flowchart TD\n") || !strings.Contains(sb.String(), "aws_") {
+		t.Errorf("Generate() =\n%s", sb.String())
+	}
+}
+
+// TestGenerate_ClassChart draws the plan fixture as a class chart, from reading the plan JSON to masking the
+// values it marks as sensitive.
+func TestGenerate_ClassChart(t *testing.T) {
+	planJSON := filepath.Join("..", "..", "internal", "testdata", "plan.json")
+	tests := []struct {
+		name  string
+		opts  Options
+		wants []string
+		avoid []string
+	}{
+		{
+			name: "default attributes",
+			opts: Options{PlanJSON: planJSON, ChartType: "class"},
+			wants: []string{
+				"class aws_vpc_main[\"aws_vpc.main\"] {\n        <<no-op>>\n        cidr_block = #quot;10.0.0.0/16#quot;\n    }\n",
+				"<<replace>>\n        ami = #quot;ami-4e5f6a7b#quot;\n        instance_type = #quot;t3.small#quot;\n    }\n",
+				"<<delete>>\n        name = #quot;web.example.com#quot;\n",
+			},
+			avoid: []string{"user_data", "tags ="},
+		},
+		{
+			name: "all attributes",
+			opts: Options{PlanJSON: planJSON, ChartType: "class", IncludeAttributes: []string{"*"}},
+			wants: []string{
+				"user_data = #40;sensitive value#41;\n",
+				"tags = #123;#quot;Tier#quot;:#quot;private#quot;#125;\n",
+			},
+			avoid: []string{"bootstrap-token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := Generate(context.Background(), tt.opts, &sb); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(sb.String(), want) {
+					t.Errorf("Generate() missing %q:\n%s", want, sb.String())
+				}
+			}
+			for _, avoid := range tt.avoid {
+				if strings.Contains(sb.String(), avoid) {
+					t.Errorf("Generate() contains %q:\n%s", avoid, sb.String())
+				}
+			}
+		})
+	}
+}
+
+// TestGenerate_Concurrent renders the same graph with different resource type settings in parallel; each diagram
+// must reflect only its own options.
+func TestGenerate_Concurrent(t *testing.T) {
+	graphFile := writeGraphFile(t)
+	tests := []struct {
+		name  string
+		opts  Options
+		wants bool // whether widget.x is drawn
+	}{
+		{name: "defaults", opts: Options{GraphFile: graphFile, ResourcesOnly: true}, wants: false},
+		{name: "prefixes", opts: Options{GraphFile: graphFile, ResourcesOnly: true, ResourceTypePrefixes: []string{"widget"}}, wants: true},
+		{name: "matcher", opts: Options{GraphFile: graphFile, ResourcesOnly: true, ResourceTypeMatcher: regexp.MustCompile(`^wid`)}, wants: true},
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		for _, tt := range tests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var sb strings.Builder
+				if err := Generate(context.Background(), tt.opts, &sb); err != nil {
+					t.Errorf("%s: Generate() error = %v", tt.name, err)
+					return
+				}
+				if got := strings.Contains(sb.String(), "widget.x"); got != tt.wants {
+					t.Errorf("%s: diagram contains widget.x = %t, want %t:\n%s", tt.name, got, tt.wants, sb.String())
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+func TestRender(t *testing.T) {
+	ctx := context.Background()
+	graph, err := LoadGraph(ctx, Options{GraphFile: writeGraphFile(t)})
+	if err != nil {
+		t.Fatalf("LoadGraph() error = %v", err)
+	}
+
+	var lr, report strings.Builder
+	if err := Render(ctx, graph, Options{Direction: "LR", ResourcesOnly: true, ClassificationReport: &report}, &lr); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(lr.String(), "flowchart LR\n") || strings.Contains(lr.String(), "var.region") {
+		t.Errorf("Render() =\n%s", lr.String())
+	}
+	if !strings.Contains(report.String(), "DROPPED  var.region") {
+		t.Errorf("classification report =\n%s", report.String())
+	}
+
+	// The same graph renders again with other options.
+	var all bytes.Buffer
+	if err := Render(ctx, graph, Options{}, &all); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(all.String(), "flowchart TD\n") || !strings.Contains(all.String(), "var.region") {
+		t.Errorf("Render() =\n%s", all.String())
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	graphFile := writeGraphFile(t)
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{name: "invalid mode", opts: Options{Mode: "dynamic"}, wantErr: errInvalidMode},
+		{name: "plan conflict", opts: Options{Plan: true, StatePull: true}, wantErr: errPlanConflict},
+		{name: "vars without plan", opts: Options{Vars: []string{"a=b"}}, wantErr: errVarsWithoutPlan},
+		{name: "invalid engine", opts: Options{Engine: "terraformer"}, wantErr: errInvalidEngine},
+		{name: "provider schema conflict", opts: Options{GraphFile: graphFile, ProviderSchema: true, ProviderSchemaFile: "schema.json"}, wantErr: errProviderSchemaConflict},
+		{name: "workspace with graph file", opts: Options{GraphFile: graphFile, Workspace: "staging"}, wantErr: errWorkspaceIncompatible},
+		{name: "workspace with state", opts: Options{State: "terraform.tfstate", Workspace: "staging"}, wantErr: errWorkspaceIncompatible},
+		{name: "workspace in static mode", opts: Options{Mode: ModeStatic, Workspace: "staging"}, wantErr: errWorkspaceIncompatible},
+		{name: "workspace with state pull", opts: Options{StatePull: true, Workspace: "staging"}, wantErr: errWorkspaceIncompatible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.WorkingDir = filepath.Join("..", "..", "test", "aws")
+			if err := Generate(context.Background(), tt.opts, &strings.Builder{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadWorkspaceGraphs_Incompatible(t *testing.T) {
+	opts := Options{WorkingDir: filepath.Join("..", "..", "test", "aws"), PlanJSON: "plan.json"}
+	if _, err := LoadWorkspaceGraphs(context.Background(), opts, nil); !errors.Is(err, errWorkspaceIncompatible) {
+		t.Errorf("LoadWorkspaceGraphs() error = %v, want %v", err, errWorkspaceIncompatible)
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{name: "defaults", opts: Options{}},
+		{name: "flowchart", opts: Options{ChartType: "flowchart", Terragrunt: true}},
+		{name: "unknown chart type", opts: Options{ChartType: "pie"}, wantErr: "unknown chart type"},
+		{name: "plantuml", opts: Options{Format: "plantuml"}},
+		{name: "plantuml chart type", opts: Options{Format: "plantuml", ChartType: "class"}, wantErr: "requires --format mermaid"},
+		{name: "terragrunt format", opts: Options{Format: "plantuml", Terragrunt: true}, wantErr: "mermaid format"},
+		{name: "draw cycles", opts: Options{DrawCycles: true, GraphType: "plan"}},
+		{name: "draw cycles without graph type", opts: Options{DrawCycles: true}, wantErr: "DrawCycles requires Options.GraphType"},
+		{name: "workspace", opts: Options{Workspace: "staging", Plan: true}},
+		{name: "workspace with plan json", opts: Options{Workspace: "staging", PlanJSON: "plan.json"}, wantErr: "workspaces require"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	types := ChartTypes()
+	if len(types) == 0 || types[0].Description == "" {
+		t.Errorf("ChartTypes() = %+v", types)
+	}
+}
+
+func TestOptions_RunsTerraform(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want bool
+	}{
+		{name: "defaults", opts: Options{}, want: true},
+		{name: "plan", opts: Options{Plan: true, Workspace: "staging"}, want: true},
+		{name: "static", opts: Options{Mode: ModeStatic}},
+		{name: "terragrunt", opts: Options{Terragrunt: true}},
+		{name: "graph file", opts: Options{GraphFile: "graph.dot"}},
+		{name: "plan json", opts: Options{PlanJSON: "plan.json"}},
+		{name: "state", opts: Options{State: "terraform.tfstate"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.RunsTerraform(); got != tt.want {
+				t.Errorf("RunsTerraform() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}